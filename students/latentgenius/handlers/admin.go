@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// adminLinksPath is the path of the links collection of AdminAPI.
+const adminLinksPath = "/api/links"
+
+// Link is a path and its destination, as exchanged with AdminAPI.
+type Link struct {
+	Path string `json:"path"`
+	URL  string `json:"url"`
+}
+
+// AdminAPI will return an http.Handler serving a JSON API to manage the
+// links of store, e.g. a DBStore over the database DBHandler serves, so
+// operators don't need to edit the backend by hand:
+//
+//	GET    /api/links         all links, as a JSON array of Link, by path
+//	POST   /api/links         create the Link in the body: 201 Created, or
+//	                          409 Conflict if the path exists; without a
+//	                          path, a random code is made up as by
+//	                          Shortener
+//	GET    /api/links/{path}  the Link of path
+//	PUT    /api/links/{path}  set the url of path to the one in the body
+//	DELETE /api/links/{path}  delete the link of path: 204 No Content
+//
+// {path} is the short path without its leading slash, so the link of
+// /promo is at /api/links/promo. Unknown paths get 404 Not Found, and
+// writes to a read-only store 405 Method Not Allowed; URLs must be
+// absolute http or https URLs. Creates and updates go through
+// Store.PutIfAbsent and Store.Replace, so of two concurrent creates of
+// one path only one succeeds, and an update racing a delete gets 404
+// rather than bringing the link back.
+//
+// The API has no authentication of its own: mount it on a separate
+// listener or behind middleware that authenticates operators.
+func AdminAPI(store Store) http.Handler {
+	return adminAPI{store}
+}
+
+type adminAPI struct {
+	store Store
+}
+
+func (a adminAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == adminLinksPath || r.URL.Path == adminLinksPath+"/" {
+		switch r.Method {
+		case http.MethodGet:
+			a.list(w)
+		case http.MethodPost:
+			a.create(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, adminLinksPath+"/")
+	if rest == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+	path := "/" + rest
+	switch r.Method {
+	case http.MethodGet:
+		a.get(w, path)
+	case http.MethodPut:
+		a.update(w, r, path)
+	case http.MethodDelete:
+		a.delete(w, path)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a adminAPI) list(w http.ResponseWriter) {
+	links, err := a.store.List()
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, links)
+}
+
+func (a adminAPI) get(w http.ResponseWriter, path string) {
+	url, ok, err := a.store.Get(path)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	if !ok {
+		http.Error(w, "404 link not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, Link{Path: path, URL: url})
+}
+
+func (a adminAPI) create(w http.ResponseWriter, r *http.Request) {
+	var link Link
+	if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := checkLinkURL(link.URL); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var err error
+	switch {
+	case link.Path == "":
+		link.Path, err = NewShortener(a.store, nil).Create(link.URL)
+	case !strings.HasPrefix(link.Path, "/"):
+		http.Error(w, fmt.Sprintf("invalid path %q: want a path starting with /", link.Path), http.StatusBadRequest)
+		return
+	default:
+		err = a.store.PutIfAbsent(link.Path, link.URL)
+	}
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, link)
+}
+
+func (a adminAPI) update(w http.ResponseWriter, r *http.Request, path string) {
+	var link Link
+	if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := checkLinkURL(link.URL); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := a.store.Replace(path, link.URL); err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Link{Path: path, URL: link.URL})
+}
+
+func (a adminAPI) delete(w http.ResponseWriter, path string) {
+	if err := a.store.Delete(path); err != nil {
+		storeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// storeError answers the request with the error err of the store.
+func storeError(w http.ResponseWriter, err error) {
+	switch err {
+	case ErrLinkNotFound:
+		http.Error(w, "404 link not found", http.StatusNotFound)
+	case ErrLinkExists:
+		http.Error(w, "409 link already exists", http.StatusConflict)
+	case ErrReadOnly:
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "405 store is read-only", http.StatusMethodNotAllowed)
+	default:
+		log.Printf("admin api: %v", err)
+		http.Error(w, "500 internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAdminAPI(t *testing.T) {
+	tests := []struct {
+		method, target, body string
+		status               int
+		want                 string // substring of the response body
+	}{
+		{"GET", "/api/links", "", http.StatusOK, `[{"path":"/promo","url":"https://example.com/promo"}]`},
+		{"GET", "/api/links/promo", "", http.StatusOK, `{"path":"/promo","url":"https://example.com/promo"}`},
+		{"GET", "/api/links/missing", "", http.StatusNotFound, "not found"},
+		{"POST", "/api/links", `{"path":"/new","url":"https://example.com/new"}`, http.StatusCreated, `"path":"/new"`},
+		{"POST", "/api/links", `{"path":"/promo","url":"https://example.com/other"}`, http.StatusConflict, "exists"},
+		{"POST", "/api/links", `{"url":"https://example.com/random"}`, http.StatusCreated, `"url":"https://example.com/random"`},
+		{"POST", "/api/links", `{"path":"new","url":"https://example.com/new"}`, http.StatusBadRequest, "invalid path"},
+		{"POST", "/api/links", `{"path":"/ftp","url":"ftp://example.com"}`, http.StatusBadRequest, "invalid url"},
+		{"POST", "/api/links", `{"path":`, http.StatusBadRequest, "invalid request body"},
+		{"PUT", "/api/links/promo", `{"url":"https://example.com/v2"}`, http.StatusOK, `"url":"https://example.com/v2"`},
+		{"PUT", "/api/links/missing", `{"url":"https://example.com/v2"}`, http.StatusNotFound, "not found"},
+		{"PUT", "/api/links/promo", `not json`, http.StatusBadRequest, "invalid request body"},
+		{"DELETE", "/api/links/promo", "", http.StatusNoContent, ""},
+		{"DELETE", "/api/links/missing", "", http.StatusNotFound, "not found"},
+		{"PATCH", "/api/links", "", http.StatusMethodNotAllowed, ""},
+		{"POST", "/api/links/promo", "", http.StatusMethodNotAllowed, ""},
+		{"GET", "/other", "", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			h := AdminAPI(NewMapStore(map[string]string{"/promo": "https://example.com/promo"}))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+			if rec.Code != tt.status || !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("%s %s = %d %q, want %d containing %q", tt.method, tt.target, rec.Code, rec.Body, tt.status, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminAPIReadOnly(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "links.json")
+	if err := os.WriteFile(name, []byte(`{"/promo": "https://example.com/promo"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileStore(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := AdminAPI(store)
+	tests := []struct {
+		method, target, body string
+		status               int
+	}{
+		{"GET", "/api/links/promo", "", http.StatusOK},
+		{"POST", "/api/links", `{"path":"/new","url":"https://example.com/new"}`, http.StatusMethodNotAllowed},
+		{"POST", "/api/links", `{"url":"https://example.com/new"}`, http.StatusMethodNotAllowed},
+		{"PUT", "/api/links/promo", `{"url":"https://example.com/v2"}`, http.StatusMethodNotAllowed},
+		{"DELETE", "/api/links/promo", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+		if rec.Code != tt.status {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.target, rec.Code, tt.status)
+		}
+	}
+}
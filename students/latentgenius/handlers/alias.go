@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// aliasPrefix marks a destination that refers to another mapped path,
+// as in "@/canonical", rather than to a URL.
+const aliasPrefix = "@"
+
+// resolveAliases replaces every alias destination in pathsToUrls with
+// the destination of the path it refers to, following chains of
+// aliases. It returns an error for aliases to unmapped paths and for
+// cycles. Paths are resolved in sorted order so the reported error is
+// the same from one load to the next.
+func resolveAliases(pathsToUrls map[string]string) error {
+	paths := make([]string, 0, len(pathsToUrls))
+	for p := range pathsToUrls {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		dst, err := resolveAlias(pathsToUrls, p)
+		if err != nil {
+			return err
+		}
+		pathsToUrls[p] = dst
+	}
+	return nil
+}
+
+func resolveAlias(pathsToUrls map[string]string, path string) (string, error) {
+	chain := []string{path}
+	seen := map[string]bool{path: true}
+	dst := pathsToUrls[path]
+	for strings.HasPrefix(dst, aliasPrefix) {
+		target := strings.TrimPrefix(dst, aliasPrefix)
+		if seen[target] {
+			return "", fmt.Errorf("alias cycle: %s -> %s", strings.Join(chain, " -> "), target)
+		}
+		next, ok := pathsToUrls[target]
+		if !ok {
+			return "", fmt.Errorf("alias %s: %s is not mapped", path, target)
+		}
+		seen[target] = true
+		chain = append(chain, target)
+		dst = next
+	}
+	return dst, nil
+}
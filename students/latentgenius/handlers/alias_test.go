@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gophercises/urlshort/students/latentgenius/handlers/handlerstest"
+)
+
+func TestYAMLAliases(t *testing.T) {
+	h, err := YAMLHandler([]byte(`
+- path: /canonical
+  url: https://example.com/page
+- path: /alias
+  url: "@/canonical"
+- path: /alias-of-alias
+  url: "@/alias"
+`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/canonical", "/alias", "/alias-of-alias"} {
+		if _, location, _ := handlerstest.ProbeHandler(h, "GET", path); location != "https://example.com/page" {
+			t.Errorf("GET %s redirected to %q, want https://example.com/page", path, location)
+		}
+	}
+}
+
+func TestResolveAliasesErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		pathsToUrls map[string]string
+		want        string
+	}{
+		{"cycle", map[string]string{"/a": "@/b", "/b": "@/a"}, "alias cycle: /a -> /b -> /a"},
+		{"self", map[string]string{"/a": "@/a"}, "alias cycle: /a -> /a"},
+		{"unmapped", map[string]string{"/a": "@/missing"}, "/missing is not mapped"},
+	}
+	for _, tt := range tests {
+		err := resolveAliases(tt.pathsToUrls)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: resolveAliases = %v, want an error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
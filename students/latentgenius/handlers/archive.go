@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const sourceArchive = "archive"
+
+// archiveMember is a regular file read from an archive.
+type archiveMember struct {
+	name string
+	data []byte
+}
+
+// ArchiveHandler will build an http.HandlerFunc from the mapping files
+// bundled in the zip or gzipped tar archive at name, so modular config
+// can ship as one artifact. The archive type is recognized by content.
+// Every .yaml, .yml, .json and .jsonc member is parsed according to its
+// extension, in the formats accepted by YAMLHandler, JSONHandler and
+// JSONCHandler, and the results are merged into one mapping; other
+// members, and hidden ones such as those under .git/ or __MACOSX/._*,
+// are skipped. Per-entry settings of YAML members are kept.
+//
+// As with LoadDir, a path defined by two members is an error naming
+// both. Parse errors name the member that caused them.
+func ArchiveHandler(name string, fallback http.Handler, opts ...Option) (http.HandlerFunc, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	members, err := readArchive(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	merged := &mapping{
+		urls:       make(map[string]string),
+		codes:      make(map[string]int),
+		folded:     make(map[string]string),
+		strict:     make(map[string]bool),
+		deprecated: make(map[string]string),
+	}
+	definedIn := make(map[string]string)
+	for _, member := range members {
+		if !mappingMember(member.name) {
+			continue
+		}
+		m, err := parseByExtension(member.name, member.data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		for key := range m.urls {
+			if other, ok := definedIn[key]; ok {
+				return nil, fmt.Errorf("%s: %s: path %q is also defined in %s", name, member.name, key, other)
+			}
+			definedIn[key] = member.name
+		}
+		for key, path := range m.folded {
+			if other, ok := merged.folded[key]; ok {
+				return nil, fmt.Errorf("%s: %s: path %s equals path %s of %s ignoring case", name, member.name, path, other, definedIn[other])
+			}
+		}
+		merged.merge(m)
+	}
+	o := newOptions(opts)
+	if err := o.checkEntries(len(merged.urls)); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return mappingHandler(sourceArchive, merged, fallback, o), nil
+}
+
+// mappingMember reports whether the archive member name is a mapping
+// file to load: one with a known extension and no hidden component.
+func mappingMember(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	switch path.Ext(name) {
+	case ".yaml", ".yml", ".json", ".jsonc":
+		return true
+	}
+	return false
+}
+
+// readArchive returns the regular files of the zip or gzipped tar
+// archive data.
+func readArchive(data []byte) ([]archiveMember, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("PK")):
+		return readZip(data)
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		return readTarGz(data)
+	}
+	return nil, fmt.Errorf("not a zip or tar.gz archive")
+}
+
+func readZip(data []byte) ([]archiveMember, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	var members []archiveMember
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+		members = append(members, archiveMember{f.Name, b})
+	}
+	return members, nil
+}
+
+func readTarGz(data []byte) ([]archiveMember, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	var members []archiveMember
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return members, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", hdr.Name, err)
+		}
+		members = append(members, archiveMember{strings.TrimPrefix(hdr.Name, "./"), b})
+	}
+}
+
+// merge adds the entries of other to m, whose maps must be non-nil.
+// The paths of other must not already be in m.
+func (m *mapping) merge(other *mapping) {
+	for key, url := range other.urls {
+		m.urls[key] = url
+	}
+	for key, code := range other.codes {
+		m.codes[key] = code
+	}
+	for key, path := range other.folded {
+		m.folded[key] = path
+	}
+	for key := range other.strict {
+		m.strict[key] = true
+	}
+	for key, msg := range other.deprecated {
+		m.deprecated[key] = msg
+	}
+	if other.limits != nil {
+		if m.limits == nil {
+			m.limits = &pathLimiter{
+				rates:   make(map[string]float64),
+				buckets: make(map[string]*tokenBucket),
+			}
+		}
+		for key, rate := range other.limits.rates {
+			m.limits.rates[key] = rate
+		}
+	}
+}
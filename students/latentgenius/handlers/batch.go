@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ResolveResult is one entry of a batch resolve response.
+type ResolveResult struct {
+	Path  string `json:"path"`
+	URL   string `json:"url,omitempty"`
+	Found bool   `json:"found"`
+}
+
+// BatchResolveHandler will return an http.HandlerFunc serving a bulk
+// resolve endpoint for bulk verification jobs. It expects a POST with a
+// JSON array of paths as the body:
+//
+//	["/some-path", "/other-path"]
+//
+// and responds with a JSON array holding one ResolveResult per path, in
+// request order:
+//
+//	[{"path":"/some-path","url":"https://www.some-url.com/demo","found":true},
+//	 {"path":"/other-path","found":false}]
+//
+// The response is streamed entry by entry rather than buffered.
+func BatchResolveHandler(pathsToUrls map[string]string) http.HandlerFunc {
+	return batchResolve(func(paths []string) (map[string]string, error) {
+		return pathsToUrls, nil
+	})
+}
+
+// DBBatchResolveHandler is like BatchResolveHandler, but resolves the
+// paths against the database used by DBHandler. All paths of a request
+// are looked up with a single IN query.
+func DBBatchResolveHandler(db *gorm.DB) http.HandlerFunc {
+	return batchResolve(func(paths []string) (map[string]string, error) {
+		var rows []urlmap
+		if err := db.Where("shortpath IN (?)", paths).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		found := make(map[string]string, len(rows))
+		for _, row := range rows {
+			found[row.Shortpath] = row.URL
+		}
+		return found, nil
+	})
+}
+
+// batchResolve implements the batch resolve endpoint on top of lookup,
+// which returns the mapped destinations of the requested paths.
+func batchResolve(lookup func(paths []string) (map[string]string, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var paths []string
+		if err := json.NewDecoder(r.Body).Decode(&paths); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		found, err := lookup(paths)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Unexpected error: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		fmt.Fprint(w, "[")
+		for i, path := range paths {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			dst, ok := found[path]
+			if err := enc.Encode(ResolveResult{Path: path, URL: dst, Found: ok}); err != nil {
+				return
+			}
+		}
+		fmt.Fprint(w, "]")
+	}
+}
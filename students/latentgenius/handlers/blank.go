@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+)
+
+// WithBlankStatus makes the handler answer paths whose mapped
+// destination is empty or blank with status code, instead of falling
+// through to the fallback.
+func WithBlankStatus(code int) Option {
+	return func(o *options) {
+		o.blankStatus = code
+	}
+}
+
+// blank guards against redirecting to an empty Location when a mapping
+// has an empty or blank destination, as happens with bad rows in the
+// database. It reports whether dst is blank, in which case it has
+// logged a data-quality warning and answered the request: with the
+// status set by WithBlankStatus, or else by calling fallback as for an
+// unmapped path.
+func (o *options) blank(w http.ResponseWriter, r *http.Request, source, dst string, fallback http.Handler) bool {
+	if strings.TrimSpace(dst) != "" {
+		return false
+	}
+	log.Printf("%s: path %s maps to a blank destination", source, r.URL.Path)
+	if o.blankStatus != 0 {
+		http.Error(w, http.StatusText(o.blankStatus), o.blankStatus)
+		return true
+	}
+	fallback.ServeHTTP(w, r)
+	return true
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WithHostBudget caps the rate of redirects the handler sends to each
+// destination host in budgets, in redirects per second with bursts of
+// up to the rate, to protect a fragile downstream rather than the
+// shortener itself. Once a host's budget is spent, requests that would
+// be redirected there get 503 Service Unavailable with Retry-After
+// instead. Hosts are matched case-insensitively, without the port;
+// hosts not in budgets, the default, are unlimited. Non-positive rates
+// are ignored. The budgets are shared by every handler built with the
+// returned Option, so a chain of sources given the same Option spends
+// one budget per host between them.
+func WithHostBudget(budgets map[string]float64) Option {
+	rates := make(map[string]float64, len(budgets))
+	for host, rate := range budgets {
+		if rate > 0 {
+			rates[strings.ToLower(host)] = rate
+		}
+	}
+	limiter := &pathLimiter{
+		rates:   rates,
+		buckets: make(map[string]*tokenBucket),
+	}
+	return func(o *options) {
+		o.hostBudget = limiter
+	}
+}
+
+// withinBudget reports whether a redirect to dst fits the budget of its
+// host; if not, it has answered the request with 503.
+func (o *options) withinBudget(w http.ResponseWriter, dst string) bool {
+	if o.hostBudget == nil {
+		return true
+	}
+	u, err := url.Parse(dst)
+	if err != nil || o.hostBudget.allow(strings.ToLower(u.Hostname())) {
+		return true
+	}
+	w.Header().Set("Retry-After", "1")
+	http.Error(w, "503 destination over budget", http.StatusServiceUnavailable)
+	return false
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CanonicalHost will return an http.HandlerFunc that normalizes every
+// request to the scheme and host of canonical, e.g.
+// "https://www.example.com", before next, typically one of the redirect
+// handlers, sees it. Requests on another host or scheme are redirected
+// with 301 Moved Permanently to the same path and query on the
+// canonical host; requests that are already canonical go straight to
+// next, so the redirect can't loop.
+//
+// The scheme of a request is https if it arrived over TLS. Behind a
+// TLS-terminating proxy, set trustProxy to take the scheme from the
+// X-Forwarded-Proto header instead; only do so if the proxy sets that
+// header itself, since clients can forge it.
+//
+// An error is returned if canonical isn't an absolute http or https
+// URL.
+func CanonicalHost(canonical string, trustProxy bool, next http.Handler) (http.HandlerFunc, error) {
+	u, err := url.Parse(canonical)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("canonical host %q must be an absolute http or https URL", canonical)
+	}
+	next = orNotFound(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if requestScheme(r, trustProxy) == u.Scheme && strings.EqualFold(r.Host, u.Host) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		dst := u.Scheme + "://" + u.Host + r.URL.RequestURI()
+		http.Redirect(w, r, dst, http.StatusMovedPermanently)
+	}, nil
+}
+
+// requestScheme returns the scheme the client used for r: https if r
+// came over TLS or, when trustProxy is set, if X-Forwarded-Proto says
+// so, and http otherwise.
+func requestScheme(r *http.Request, trustProxy bool) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	if trustProxy {
+		proto := r.Header.Get("X-Forwarded-Proto")
+		// A chain of proxies may append; the first value is the client's.
+		if i := strings.IndexByte(proto, ','); i >= 0 {
+			proto = proto[:i]
+		}
+		if strings.EqualFold(strings.TrimSpace(proto), "https") {
+			return "https"
+		}
+	}
+	return "http"
+}
+
+// UpgradeToHTTPS will return an http.HandlerFunc redirecting requests
+// that came over plain http to the same host, path and query over
+// https with 301 Moved Permanently, before next sees them. The scheme is
+// detected as for CanonicalHost, including trustProxy, and only
+// requests that are genuinely insecure are redirected, so the upgrade
+// can't loop. Any port in the host is dropped, so the https URL uses
+// the default port.
+func UpgradeToHTTPS(trustProxy bool, next http.Handler) http.HandlerFunc {
+	next = orNotFound(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if requestScheme(r, trustProxy) == "https" || r.Host == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+			if strings.Contains(host, ":") {
+				host = "[" + host + "]"
+			}
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"log"
+	"sort"
+)
+
+// WithCanonicalize makes the map-based handlers (MapHandler,
+// YAMLHandler, JSONHandler and the like) match paths up to canonicalize,
+// a normalization such as lowercasing, dropping a trailing slash,
+// stripping a locale prefix or collapsing double slashes: a request
+// matches an entry if both paths canonicalize to the same string. The
+// handler applies canonicalize to the mapped paths once, when it is
+// built, and to each request path, so the same function is always used
+// on both sides.
+//
+// An exact match still takes precedence, then an ignore_case match,
+// then a canonical one. If several mapped paths canonicalize to the
+// same string, the first in sorted order wins and the others are
+// logged, since requests can only reach one of them. The hook must be
+// safe for concurrent use.
+func WithCanonicalize(canonicalize func(path string) string) Option {
+	return func(o *options) {
+		o.canonicalize = canonicalize
+	}
+}
+
+// withCanonical returns a copy of m indexed by canonicalize.
+func (m *mapping) withCanonical(source string, canonicalize func(string) string) *mapping {
+	paths := make([]string, 0, len(m.urls))
+	for path := range m.urls {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	canon := make(map[string]string, len(paths))
+	for _, path := range paths {
+		key := canonicalize(path)
+		if other, ok := canon[key]; ok {
+			log.Printf("%s: path %s is shadowed by %s, which canonicalizes to the same %s", source, path, other, key)
+			continue
+		}
+		canon[key] = path
+	}
+	c := *m
+	c.canon = canon
+	c.canonicalize = canonicalize
+	return &c
+}
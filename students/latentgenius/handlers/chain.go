@@ -0,0 +1,29 @@
+package handlers
+
+import "net/http"
+
+// Source is a tier of a Chain: given the handler for requests it
+// doesn't match, it returns its own. The source handlers fit it once
+// their other arguments are bound, e.g.
+//
+//	func(next http.Handler) http.Handler {
+//		return handlers.MapHandler(pathsToUrls, next)
+//	}
+type Source func(fallback http.Handler) http.Handler
+
+// Chain will return an http.Handler resolving requests through sources
+// in order, each falling through to the next on a miss and the last to
+// terminal, such as a redirect home or JSON404Handler; a nil terminal
+// means http.NotFoundHandler. This is the nesting of fallbacks done by
+// hand otherwise, written in the order it is tried:
+//
+//	handlers.Chain([]handlers.Source{fromDB, fromYAML}, home)
+//
+// Each source keeps its own behaviour, such as its redirect statuses.
+func Chain(sources []Source, terminal http.Handler) http.Handler {
+	h := orNotFound(terminal)
+	for i := len(sources) - 1; i >= 0; i-- {
+		h = sources[i](h)
+	}
+	return h
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gophercises/urlshort/students/latentgenius/handlers/handlerstest"
+)
+
+func TestChain(t *testing.T) {
+	fromMap := func(next http.Handler) http.Handler {
+		return MapHandler(map[string]string{"/map": "https://example.com/map"}, next)
+	}
+	fromYAML := func(next http.Handler) http.Handler {
+		h, err := YAMLHandler([]byte("- path: /yaml\n  url: https://example.com/yaml\n  status: 301\n"), next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	home := http.RedirectHandler("https://example.com/", http.StatusSeeOther)
+	h := Chain([]Source{fromMap, fromYAML}, home)
+
+	tests := []struct {
+		path     string
+		status   int
+		location string
+	}{
+		{"/map", http.StatusFound, "https://example.com/map"},
+		{"/yaml", http.StatusMovedPermanently, "https://example.com/yaml"},
+		{"/miss", http.StatusSeeOther, "https://example.com/"},
+	}
+	for _, tt := range tests {
+		status, location, _ := handlerstest.ProbeHandler(h, "GET", tt.path)
+		if status != tt.status || location != tt.location {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.path, status, location, tt.status, tt.location)
+		}
+	}
+}
+
+func TestChainNilTerminal(t *testing.T) {
+	h := Chain(nil, nil)
+	if status, _, _ := handlerstest.ProbeHandler(h, "GET", "/miss"); status != http.StatusNotFound {
+		t.Errorf("GET /miss = %d, want %d", status, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const sourceChoices = "choices"
+
+// Choice is one labeled destination of an ambiguous path.
+type Choice struct {
+	Label string
+	URL   string
+}
+
+// ChoicePage is what the choice page template is executed with.
+type ChoicePage struct {
+	Path    string
+	Choices []Choice
+}
+
+// defaultChoicePage is the choice page used unless WithChoicePage sets
+// another.
+var defaultChoicePage = template.Must(template.New("choices").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Path}}</title></head>
+<body>
+<p>{{.Path}} may refer to:</p>
+<ul>
+{{range .Choices}}<li><a href="{{.URL}}">{{.Label}}</a></li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+// WithChoicePage makes ChoicesHandler render its choice pages with
+// tmpl, executed with a ChoicePage, instead of a plain list of links.
+func WithChoicePage(tmpl *template.Template) Option {
+	return func(o *options) {
+		o.choicePage = tmpl
+	}
+}
+
+// ChoicesHandler will return an http.HandlerFunc that answers each path
+// in pathsToChoices with 300 Multiple Choices and an HTML page listing
+// its destinations, letting the user pick one, e.g. for a /mercury that
+// could mean the planet or the element. For machine clients, each
+// choice is also sent in a Link header (RFC 8288) with rel="alternate"
+// and the label as title. Choices whose URL has a disallowed scheme are
+// left out; see WithAllowedSchemes. Paths without choices fall through
+// to the fallback http.Handler.
+//
+// A template that fails when executed is logged and answered with 500
+// Internal Server Error.
+func ChoicesHandler(pathsToChoices map[string][]Choice, fallback http.Handler, opts ...Option) http.HandlerFunc {
+	o := newOptions(opts)
+	fallback = o.missFallback(sourceChoices, fallback)
+	page := o.choicePage
+	if page == nil {
+		page = defaultChoicePage
+	}
+
+	return o.handler(sourceChoices, func(w http.ResponseWriter, r *http.Request) {
+		if o.serveRoot(w, r, sourceChoices, fallback) {
+			return
+		}
+		var choices []Choice
+		for _, c := range pathsToChoices[r.URL.Path] {
+			if !o.schemeAllowed(c.URL) {
+				log.Printf("%s: leaving %q out of the choices for %s: scheme not allowed", sourceChoices, c.URL, r.URL.Path)
+				continue
+			}
+			choices = append(choices, c)
+		}
+		if len(choices) == 0 {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		var buf bytes.Buffer
+		if err := page.Execute(&buf, ChoicePage{Path: r.URL.Path, Choices: choices}); err != nil {
+			log.Printf("%s: path %s: %v", sourceChoices, r.URL.Path, err)
+			http.Error(w, "500 internal server error", http.StatusInternalServerError)
+			return
+		}
+		for _, c := range choices {
+			w.Header().Add("Link", "<"+c.URL+`>; rel="alternate"; title=`+strconv.Quote(c.Label))
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusMultipleChoices)
+		w.Write(buf.Bytes())
+	})
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// compressMinSize is the smallest body Compress bothers to gzip.
+const compressMinSize = 1024
+
+// Compress will return an http.HandlerFunc that gzip-encodes the
+// responses of next for clients sending Accept-Encoding: gzip. It is
+// meant for endpoints with sizable bodies, such as the batch resolver;
+// it leaves alone redirect responses, whose bodies are trivial, bodies
+// under 1KB, and content that is compressed already (images, audio,
+// video, archives, or anything with a Content-Encoding). Responses get
+// Vary: Accept-Encoding so caches keep the encodings apart.
+func Compress(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipWriter{ResponseWriter: w, code: http.StatusOK}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	}
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		fields := strings.Split(part, ";")
+		if strings.TrimSpace(fields[0]) != "gzip" {
+			continue
+		}
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipWriter holds back the start of the body until it knows whether
+// the response is worth compressing.
+type gzipWriter struct {
+	http.ResponseWriter
+	code    int
+	buf     []byte
+	decided bool
+	gz      *gzip.Writer
+}
+
+func (gw *gzipWriter) WriteHeader(code int) {
+	if !gw.decided {
+		gw.code = code
+	}
+}
+
+func (gw *gzipWriter) Write(b []byte) (int, error) {
+	if !gw.decided {
+		gw.buf = append(gw.buf, b...)
+		if len(gw.buf) < compressMinSize {
+			return len(b), nil
+		}
+		if err := gw.decide(gw.compressible()); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if gw.gz != nil {
+		return gw.gz.Write(b)
+	}
+	return gw.ResponseWriter.Write(b)
+}
+
+// Flush sends what has been written so far, deciding on compression
+// early if need be.
+func (gw *gzipWriter) Flush() {
+	if !gw.decided {
+		gw.decide(len(gw.buf) > 0 && gw.compressible())
+	}
+	if gw.gz != nil {
+		gw.gz.Flush()
+	}
+	if f, ok := gw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (gw *gzipWriter) close() {
+	if !gw.decided {
+		// Everything fitted in the buffer, so the body is tiny.
+		gw.decide(false)
+	}
+	if gw.gz != nil {
+		gw.gz.Close()
+	}
+}
+
+// decide writes the header and the buffered body, through a gzip
+// writer if compress is set.
+func (gw *gzipWriter) decide(compress bool) error {
+	gw.decided = true
+	if compress {
+		h := gw.Header()
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		gw.gz = gzip.NewWriter(gw.ResponseWriter)
+	}
+	gw.ResponseWriter.WriteHeader(gw.code)
+	buf := gw.buf
+	gw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if gw.gz != nil {
+		_, err = gw.gz.Write(buf)
+	} else {
+		_, err = gw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (gw *gzipWriter) compressible() bool {
+	if gw.code < http.StatusOK || gw.code >= http.StatusMultipleChoices && gw.code < http.StatusBadRequest ||
+		gw.code == http.StatusNoContent {
+		return false
+	}
+	h := gw.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := h.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(gw.buf)
+		h.Set("Content-Type", contentType)
+	}
+	return !compressedType(contentType)
+}
+
+// compressedType reports whether content of the given type is typically
+// compressed already.
+func compressedType(contentType string) bool {
+	for _, prefix := range []string{"image/", "audio/", "video/",
+		"application/zip", "application/gzip", "application/x-gzip",
+		"application/x-bzip2", "application/x-xz", "application/zstd"} {
+		if strings.HasPrefix(contentType, prefix) {
+			return !strings.HasPrefix(contentType, "image/svg")
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const sourceConsul = "consul"
+
+const (
+	// consulWait is how long a blocking query waits for a change.
+	consulWait = 5 * time.Minute
+	// consulRetry is the pause after a failed watch query.
+	consulRetry = 5 * time.Second
+)
+
+// ConsulHandler will return an http.HandlerFunc serving the mapping
+// kept in the Consul KV store under prefix, read through the HTTP API
+// of the agent at addr, e.g. "http://127.0.0.1:8500". Each key under
+// prefix is a path and its value the URL it maps to: with the prefix
+// "redirects/", the key redirects/promo maps /promo. Keys ending in a
+// slash, as created for folders, are ignored. The ACL token, if any, is
+// read from the CONSUL_HTTP_TOKEN environment variable, as the consul
+// command does.
+//
+// The prefix is watched with blocking queries, so changes are picked up
+// as soon as they are made and atomically replace the mapping in use.
+// If a query fails, the last good mapping keeps being served, the error
+// is logged and the watch is retried after 5s. Paths with no key fall
+// through to the fallback.
+//
+// An error is returned if the initial read fails. The returned stop
+// function ends the watch.
+func ConsulHandler(addr, prefix string, fallback http.Handler, opts ...Option) (http.HandlerFunc, func(), error) {
+	o := newOptions(opts)
+	kv := &consulKV{
+		addr:   strings.TrimSuffix(addr, "/"),
+		prefix: prefix,
+		token:  os.Getenv("CONSUL_HTTP_TOKEN"),
+		client: &http.Client{Timeout: consulWait + remoteTimeout},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	pathMap, index, err := kv.list(ctx, 0)
+	if err == nil {
+		err = o.checkEntries(len(pathMap))
+	}
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	index = consulIndex(0, index)
+	var current atomic.Value
+	current.Store(mapHandler(sourceConsul, pathMap, fallback, o))
+
+	go func() {
+		for ctx.Err() == nil {
+			pathMap, next, err := kv.list(ctx, index)
+			if err == nil {
+				err = o.checkEntries(len(pathMap))
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Printf("%s %s: %v", sourceConsul, prefix, err)
+				select {
+				case <-time.After(consulRetry):
+				case <-ctx.Done():
+				}
+				continue
+			}
+			if next != index {
+				current.Store(mapHandler(sourceConsul, pathMap, fallback, o))
+			}
+			index = consulIndex(index, next)
+		}
+	}()
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		current.Load().(http.HandlerFunc)(w, r)
+	}
+	return h, cancel, nil
+}
+
+// consulIndex returns the index to block on after a query at prev
+// answered with next. As Consul advises, an index going backwards, as
+// when the store was reset, or a zero one restarts from 1: a zero index
+// would make the next query non-blocking, and the watch a busy loop.
+func consulIndex(prev, next uint64) uint64 {
+	if next == 0 || next < prev {
+		return 1
+	}
+	return next
+}
+
+// consulKV reads a key prefix from the Consul KV HTTP API.
+type consulKV struct {
+	addr   string
+	prefix string
+	token  string
+	client *http.Client
+}
+
+// list reads every key under the prefix. With a non-zero index, it is a
+// blocking query returning once the prefix has changed since index, or
+// after consulWait. It returns the mapping and the index it is current
+// as of.
+func (kv *consulKV) list(ctx context.Context, index uint64) (map[string]string, uint64, error) {
+	query := url.Values{"recurse": {""}}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", consulWait.String())
+	}
+	reqURL := kv.addr + "/v1/kv/" + kv.prefix + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if kv.token != "" {
+		req.Header.Set("X-Consul-Token", kv.token)
+	}
+	resp, err := kv.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	next, err := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid X-Consul-Index %q", resp.Header.Get("X-Consul-Index"))
+	}
+	pathMap := make(map[string]string)
+	if resp.StatusCode == http.StatusNotFound {
+		// No key under the prefix.
+		return pathMap, next, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var pairs []struct {
+		Key   string
+		Value []byte // base64 in the JSON
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, 0, err
+	}
+	for _, pair := range pairs {
+		if strings.HasSuffix(pair.Key, "/") {
+			continue
+		}
+		path := strings.TrimPrefix(pair.Key, kv.prefix)
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		pathMap[path] = string(pair.Value)
+	}
+	return pathMap, next, nil
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeConsul is a Consul KV endpoint answering each query with the next
+// of its replies, and blocking once they run out.
+type fakeConsul struct {
+	queries chan string // index parameter of each query
+	replies chan fakeConsulReply
+}
+
+type fakeConsulReply struct {
+	index   uint64
+	pathMap map[string]string // keys relative to the prefix
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.queries <- r.URL.Query().Get("index")
+	var reply fakeConsulReply
+	select {
+	case reply = <-f.replies:
+	case <-r.Context().Done():
+		return
+	}
+	type pair struct {
+		Key   string
+		Value []byte
+	}
+	var pairs []pair
+	for k, v := range reply.pathMap {
+		pairs = append(pairs, pair{"redirects/" + k, []byte(v)})
+	}
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(reply.index, 10))
+	json.NewEncoder(w).Encode(pairs)
+}
+
+func (f *fakeConsul) nextQuery(t *testing.T) string {
+	t.Helper()
+	select {
+	case q := <-f.queries:
+		return q
+	case <-time.After(5 * time.Second):
+		t.Fatal("no query")
+		return ""
+	}
+}
+
+func TestConsulHandler(t *testing.T) {
+	fake := &fakeConsul{queries: make(chan string, 10), replies: make(chan fakeConsulReply, 10)}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	fake.replies <- fakeConsulReply{0, map[string]string{"promo": "https://example.com/v1"}}
+	h, stop, err := ConsulHandler(srv.URL, "redirects/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	if q := fake.nextQuery(t); q != "" {
+		t.Errorf("initial query index = %q, want none", q)
+	}
+	probe := func(want string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/promo", nil))
+		if got := rec.Header().Get("Location"); got != want {
+			t.Errorf("GET /promo redirected to %q, want %q", got, want)
+		}
+	}
+	probe("https://example.com/v1")
+
+	// A zero index blocks from 1, not from 0.
+	if q := fake.nextQuery(t); q != "1" {
+		t.Errorf("watch query index = %q, want 1", q)
+	}
+	fake.replies <- fakeConsulReply{7, map[string]string{"promo": "https://example.com/v2"}}
+	if q := fake.nextQuery(t); q != "7" {
+		t.Errorf("watch query index = %q, want 7", q)
+	}
+	probe("https://example.com/v2")
+
+	// An index going backwards restarts from 1.
+	fake.replies <- fakeConsulReply{3, map[string]string{"promo": "https://example.com/v3"}}
+	if q := fake.nextQuery(t); q != "1" {
+		t.Errorf("watch query index = %q, want 1", q)
+	}
+	probe("https://example.com/v3")
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import "net/http"
+
+const sourceCookie = "cookie"
+
+// CookieRule picks the destination of a path from the value of a
+// cookie: the request is redirected to Destinations[value] of the
+// cookie named Cookie, or to Default if the cookie is missing or its
+// value isn't listed.
+type CookieRule struct {
+	Cookie       string
+	Destinations map[string]string
+	Default      string
+}
+
+// CookieHandler will return an http.HandlerFunc that redirects each
+// path in pathsToRules according to a cookie, e.g. sending /portal to a
+// different tool depending on a role cookie. Paths without a rule, and
+// requests for which the rule gives no destination (no match and no
+// Default), fall through to the fallback http.Handler.
+//
+// The handler trusts the cookie as sent: anyone can set any value. Only
+// use it to choose among destinations that enforce their own access
+// control, or with cookies that are signed and verified in front of
+// it. Responses carry Vary: Cookie, so caches keep them apart.
+func CookieHandler(pathsToRules map[string]CookieRule, fallback http.Handler, opts ...Option) http.HandlerFunc {
+	o := newOptions(opts)
+	fallback = o.missFallback(sourceCookie, fallback)
+
+	return o.handler(sourceCookie, func(w http.ResponseWriter, r *http.Request) {
+		if o.serveRoot(w, r, sourceCookie, fallback) {
+			return
+		}
+		rule, ok := pathsToRules[r.URL.Path]
+		if !ok {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Cookie")
+		dst := rule.Default
+		if c, err := r.Cookie(rule.Cookie); err == nil {
+			if d, ok := rule.Destinations[c.Value]; ok {
+				dst = d
+			}
+		}
+		if dst == "" {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		o.redirect(w, r, sourceCookie, dst, http.StatusFound, fallback)
+	})
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// counters are the process-wide activity counters of the handlers.
+var counters struct {
+	requests  int64
+	redirects int64
+	fallbacks int64
+}
+
+// Counters is a snapshot of the activity of all the handlers in the
+// process. A request passing through several chained handlers is
+// counted once by each of them.
+type Counters struct {
+	Requests  int64 `json:"requests"`  // requests served by the source handlers
+	Redirects int64 `json:"redirects"` // redirects answered
+	Fallbacks int64 `json:"fallbacks"` // requests passed on to a fallback
+}
+
+// ReadCounters returns the current activity counters. Package
+// handlersexpvar publishes them with expvar.
+func ReadCounters() Counters {
+	return Counters{
+		Requests:  atomic.LoadInt64(&counters.requests),
+		Redirects: atomic.LoadInt64(&counters.redirects),
+		Fallbacks: atomic.LoadInt64(&counters.fallbacks),
+	}
+}
+
+// countFallback wraps fallback so that its requests are counted.
+func countFallback(fallback http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&counters.fallbacks, 1)
+		fallback.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const sourceSheet = "sheet"
+
+// SheetHandler will return an http.HandlerFunc serving the mapping kept
+// in a spreadsheet published to the web as CSV, such as a Google Sheet
+// shared with "File > Share > Publish to web", so non-technical teams
+// can maintain links. csvURL is fetched and parsed as CSV regardless of
+// its Content-Type; otherwise it behaves like RemoteHandler: the sheet
+// is refetched every interval, swapped in atomically on success, kept
+// at its last good version on failure, and not downloaded again while
+// the server's ETag or Last-Modified say it is unchanged.
+//
+// The sheet's first column holds paths and its second the URLs they
+// map to; further columns are ignored, as is a first row whose first
+// cell reads "path". Rows with an empty path are skipped:
+//
+//	path,url
+//	/some-path,https://www.some-url.com/demo
+func SheetHandler(csvURL string, interval time.Duration, fallback http.Handler, opts ...Option) (http.HandlerFunc, func(), error) {
+	src := &remoteSource{
+		url:    csvURL,
+		client: &http.Client{Timeout: remoteTimeout},
+		parse: func(_ string, data []byte) (*mapping, error) {
+			pathMap, err := parseCSV(data)
+			if err != nil {
+				return nil, err
+			}
+			return &mapping{urls: pathMap}, nil
+		},
+	}
+	return pollRemote(sourceSheet, src, interval, fallback, newOptions(opts))
+}
+
+func parseCSV(data []byte) (map[string]string, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	dst := make(map[string]string)
+	for first := true; ; first = false {
+		record, err := r.Read()
+		if err == io.EOF {
+			return dst, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		path := strings.TrimSpace(record[0])
+		if first && strings.EqualFold(path, "path") || path == "" {
+			continue
+		}
+		if len(record) < 2 {
+			line, _ := r.FieldPos(0)
+			return nil, fmt.Errorf("csv: line %d: no url for path %s", line, path)
+		}
+		dst[path] = strings.TrimSpace(record[1])
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseCSV(t *testing.T) {
+	got, err := parseCSV([]byte("path,url,owner\n/a,https://example.com/a,ann\n,https://example.com/skipped\n/b,https://example.com/b\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"/a": "https://example.com/a", "/b": "https://example.com/b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCSV = %v, want %v", got, want)
+	}
+}
+
+func TestSheetHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Published sheets are often served as text/plain.
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "/a,https://example.com/a\n")
+	}))
+	defer srv.Close()
+
+	h, stop, err := SheetHandler(srv.URL, time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/a", nil))
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "https://example.com/a" {
+		t.Errorf("GET /a = %d %q, want 302 https://example.com/a", rec.Code, rec.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// DBOpKind is the kind of change a DBOp makes.
+type DBOpKind int
+
+const (
+	// DBCreate adds a new link; it fails if the path exists.
+	DBCreate DBOpKind = iota
+	// DBUpdate changes the URL of an existing link.
+	DBUpdate
+	// DBDelete removes an existing link.
+	DBDelete
+)
+
+func (k DBOpKind) String() string {
+	switch k {
+	case DBCreate:
+		return "create"
+	case DBUpdate:
+		return "update"
+	case DBDelete:
+		return "delete"
+	}
+	return fmt.Sprintf("DBOpKind(%d)", int(k))
+}
+
+// DBOp is one change to the urlmaps table used by DBHandler. URL is
+// ignored by DBDelete.
+type DBOp struct {
+	Kind DBOpKind
+	Path string
+	URL  string
+}
+
+// ApplyDB applies ops to the urlmaps table in order, all or nothing, in
+// a single transaction, e.g. to swap the links of a campaign without
+// ever serving half of the change. Updating or deleting a path that
+// isn't in the table is an error. On the first failing operation the
+// whole transaction is rolled back and the error names the operation,
+// by index and content.
+func ApplyDB(db *gorm.DB, ops []DBOp) error {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	for i, op := range ops {
+		if err := applyDBOp(tx, op); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("operation %d (%s %s): %v", i, op.Kind, op.Path, err)
+		}
+	}
+	return tx.Commit().Error
+}
+
+func applyDBOp(tx *gorm.DB, op DBOp) error {
+	var res *gorm.DB
+	switch op.Kind {
+	case DBCreate:
+		return tx.Create(&urlmap{Shortpath: op.Path, URL: op.URL}).Error
+	case DBUpdate:
+		// Some databases count only the rows actually changed, so
+		// check that the path exists rather than rely on RowsAffected.
+		var n int
+		if err := tx.Model(&urlmap{}).Where("shortpath = ?", op.Path).Count(&n).Error; err != nil {
+			return err
+		}
+		if n == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&urlmap{}).Where("shortpath = ?", op.Path).Update("url", op.URL).Error
+	case DBDelete:
+		res = tx.Where("shortpath = ?", op.Path).Delete(&urlmap{})
+	default:
+		return fmt.Errorf("unknown operation kind %d", int(op.Kind))
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// checkTimeout bounds each request made by CheckDestinations.
+const checkTimeout = 10 * time.Second
+
+// DestResult is the outcome of checking the destination of one path.
+// Status is 0 if no response was received, in which case Err says why.
+type DestResult struct {
+	Path        string
+	Destination string
+	Status      int
+	Err         error
+}
+
+// CheckDestinations requests the destination of every path in m, to
+// find dead links before a mapping goes live. Each destination gets a
+// HEAD request, retried as a GET if the server refuses HEAD (405 or
+// 501); redirects are not followed, so a redirecting destination shows
+// up with its 3xx status. At most concurrency requests are in flight at
+// once, each timing out after 10s. Destinations that aren't http or
+// https URLs, such as GoneURL, are skipped.
+//
+// The results are sorted by path. One whose Status is 0 or 400 and
+// above points at a dead link. If ctx is canceled, the check stops and
+// ctx's error is returned with the results gathered so far.
+func CheckDestinations(ctx context.Context, m map[string]string, concurrency int) ([]DestResult, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	client := &http.Client{
+		Timeout: checkTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	var (
+		mu      sync.Mutex
+		results []DestResult
+		wg      sync.WaitGroup
+	)
+	sem := make(chan struct{}, concurrency)
+	for path, dst := range m {
+		if !strings.HasPrefix(dst, "http://") && !strings.HasPrefix(dst, "https://") {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(path, dst string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			status, err := checkDestination(ctx, client, dst)
+			mu.Lock()
+			results = append(results, DestResult{Path: path, Destination: dst, Status: status, Err: err})
+			mu.Unlock()
+		}(path, dst)
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	return results, ctx.Err()
+}
+
+func checkDestination(ctx context.Context, client *http.Client, dst string) (int, error) {
+	status, err := probeRequest(ctx, client, http.MethodHead, dst)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = probeRequest(ctx, client, http.MethodGet, dst)
+	}
+	return status, err
+}
+
+func probeRequest(ctx context.Context, client *http.Client, method, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
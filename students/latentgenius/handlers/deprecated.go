@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// defaultDeprecationMessage is the warning given for deprecated entries
+// without a deprecation_message.
+const defaultDeprecationMessage = "this link is deprecated and will be removed"
+
+// buildDeprecated collects the warnings of the entries with
+// deprecated: true, keyed by path.
+func buildDeprecated(entries []map[string]string) map[string]string {
+	deprecated := make(map[string]string)
+	for _, entry := range entries {
+		if entry["deprecated"] != "true" {
+			continue
+		}
+		msg := entry["deprecation_message"]
+		if msg == "" {
+			msg = defaultDeprecationMessage
+		}
+		deprecated[entry["path"]] = msg
+	}
+	return deprecated
+}
+
+// warnDeprecated adds a Warning header (RFC 7234 code 299, a persistent
+// miscellaneous warning) to the response for a deprecated entry, and
+// logs the use of the entry.
+func warnDeprecated(w http.ResponseWriter, r *http.Request, source, msg string) {
+	w.Header().Add("Warning", "299 - "+strconv.Quote(msg))
+	log.Printf("%s: deprecated path %s requested: %s", source, r.URL.Path, msg)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+)
+
+const sourceEmbed = "embed"
+
+// EmbedHandler will build an http.HandlerFunc from the mapping file
+// name in fsys, typically an embed.FS compiled into the binary with
+// go:embed, so single-binary deployments need no config file at run
+// time. The format is chosen by the file extension: .yaml or .yml for
+// YAML, .json for JSON, .jsonc for commented JSON and .txt for plain
+// text, in the formats accepted by YAMLHandler, JSONHandler,
+// JSONCHandler and TextHandler.
+//
+// An error is returned if the file is missing from fsys, has an
+// unknown extension or can't be parsed.
+func EmbedHandler(fsys fs.FS, name string, fallback http.Handler, opts ...Option) (http.HandlerFunc, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("embed: %s is missing from the embedded files: %w", name, err)
+		}
+		return nil, err
+	}
+	o := newOptions(opts)
+	m, err := parseByExtension(name, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.checkEntries(len(m.urls)); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return mappingHandler(sourceEmbed, m, fallback, o), nil
+}
+
+// parseByExtension parses data as YAML, JSON, JSONC or text according
+// to the extension of the file name it was read from.
+func parseByExtension(name string, data []byte) (*mapping, error) {
+	var pathMap map[string]string
+	var err error
+	switch ext := path.Ext(name); ext {
+	case ".yaml", ".yml":
+		parsedYaml, err := parseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		m, err := buildMapping(parsedYaml)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		return m, nil
+	case ".json":
+		pathMap, err = parseJSON(data)
+	case ".jsonc":
+		pathMap, err = parseJSONC(data)
+	case ".txt":
+		m, err := parseText(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("%s: unknown mapping format %q", name, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return &mapping{urls: pathMap}, nil
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+const sourceExtension = "extension"
+
+// ExtensionRule picks the destination of a base path from the file
+// extension the path was requested with: a request for the base path
+// plus ".ext" is redirected to Extensions["ext"], and one for the bare
+// base path, or with an extension that isn't listed, to Default.
+// Extensions are given without the dot and matched case-insensitively.
+type ExtensionRule struct {
+	Extensions map[string]string
+	Default    string
+}
+
+// ExtensionHandler will return an http.HandlerFunc that redirects each
+// base path in pathsToRules, with or without a file extension,
+// according to the extension. With
+//
+//	ExtensionHandler(map[string]ExtensionRule{
+//		"/report": {
+//			Extensions: map[string]string{
+//				"pdf": "https://files.example/report.pdf",
+//				"csv": "https://files.example/report.csv",
+//			},
+//			Default: "https://app.example/report",
+//		},
+//	}, nil)
+//
+// /report.pdf and /report.csv redirect to the files and /report to the
+// HTML view. A path that is itself in pathsToRules is never split, so
+// base paths may contain dots. Paths without a rule, and requests for
+// which the rule gives no destination (an unlisted extension and no
+// Default), fall through to the fallback http.Handler.
+func ExtensionHandler(pathsToRules map[string]ExtensionRule, fallback http.Handler, opts ...Option) http.HandlerFunc {
+	o := newOptions(opts)
+	fallback = o.missFallback(sourceExtension, fallback)
+
+	rules := make(map[string]ExtensionRule, len(pathsToRules))
+	for p, rule := range pathsToRules {
+		exts := make(map[string]string, len(rule.Extensions))
+		for ext, dst := range rule.Extensions {
+			exts[strings.ToLower(strings.TrimPrefix(ext, "."))] = dst
+		}
+		rules[p] = ExtensionRule{Extensions: exts, Default: rule.Default}
+	}
+
+	return o.handler(sourceExtension, func(w http.ResponseWriter, r *http.Request) {
+		if o.serveRoot(w, r, sourceExtension, fallback) {
+			return
+		}
+		dst := ""
+		if rule, ok := rules[r.URL.Path]; ok {
+			dst = rule.Default
+		} else if ext := path.Ext(r.URL.Path); ext != "" {
+			if rule, ok := rules[strings.TrimSuffix(r.URL.Path, ext)]; ok {
+				dst = rule.Default
+				if d, ok := rule.Extensions[strings.ToLower(ext[1:])]; ok {
+					dst = d
+				}
+			}
+		}
+		if dst == "" {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		o.redirect(w, r, sourceExtension, dst, http.StatusFound, fallback)
+	})
+}
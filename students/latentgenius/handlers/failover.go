@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	sourceFailover     = "failover"
+	sourceFirstHealthy = "firsthealthy"
+)
+
+// defaultHealthInterval is how often destinations are probed when no
+// positive interval is given.
+const defaultHealthInterval = 30 * time.Second
+
+// Failover is a health-checked destination: requests are redirected to
+// Primary while it is reachable, and to Backup while it isn't.
+type Failover struct {
+	Primary string
+	Backup  string
+}
+
+// FailoverHandler will return an http.HandlerFunc that redirects each
+// path in pathsToFailovers to its primary destination, or to its backup
+// destination while the primary appears to be down. If the path is not
+// provided in the map, then the fallback http.Handler will be called
+// instead.
+//
+// The primaries are probed with a HEAD request every interval, or every
+// 30s if interval isn't positive, in the background, so probing adds no
+// latency to requests. A primary counts
+// as down when the probe fails or it answers with a 5xx status; until
+// the first probe completes, primaries are assumed to be up.
+//
+// The returned stop function ends the background probing. Only paths
+// listed in pathsToFailovers are health-checked, so high-value links
+// can opt in while the rest are served by plain handlers.
+func FailoverHandler(pathsToFailovers map[string]Failover, interval time.Duration, fallback http.Handler, opts ...Option) (http.HandlerFunc, func()) {
+	o := newOptions(opts)
+	fallback = o.missFallback(sourceFailover, fallback)
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+	urls := make([]string, 0, len(pathsToFailovers))
+	for _, f := range pathsToFailovers {
+		urls = append(urls, f.Primary)
+	}
+	hc := newHealthChecker(urls, interval, headProbe(interval))
+
+	return o.handler(sourceFailover, func(w http.ResponseWriter, r *http.Request) {
+		if o.serveRoot(w, r, sourceFailover, fallback) {
+			return
+		}
+		f, ok := pathsToFailovers[r.URL.Path]
+		if !ok {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		dst := f.Primary
+		if !hc.healthy(f.Primary) && f.Backup != "" {
+			dst = f.Backup
+		}
+		if o.blank(w, r, sourceFailover, dst, fallback) {
+			return
+		}
+		o.redirect(w, r, sourceFailover, dst, http.StatusFound, fallback)
+	}), hc.stop
+}
+
+// HealthCheck configures how FirstHealthyHandler probes destinations.
+// Zero values get defaults.
+type HealthCheck struct {
+	Interval time.Duration // between probes; defaults to 30s
+	Timeout  time.Duration // of each probe; defaults to Interval
+	Method   string        // of the probe request; defaults to HEAD
+	Status   int           // expected status; 0 accepts any below 500
+}
+
+// FirstHealthyHandler will return an http.HandlerFunc that redirects
+// each path in pathsToDestinations to the first of its ordered
+// destinations that is currently up, so mirrors take over in order of
+// preference. If all of them are down, or the path is not provided in
+// the map, then the fallback http.Handler will be called instead.
+//
+// Destinations are probed in the background as configured by check,
+// and are assumed to be up until their first probe completes. A
+// destination is down when the probe fails or doesn't answer with the
+// expected status. The returned stop function ends the probing.
+func FirstHealthyHandler(pathsToDestinations map[string][]string, check HealthCheck, fallback http.Handler, opts ...Option) (http.HandlerFunc, func()) {
+	o := newOptions(opts)
+	fallback = o.missFallback(sourceFirstHealthy, fallback)
+	if check.Interval <= 0 {
+		check.Interval = defaultHealthInterval
+	}
+	if check.Timeout <= 0 {
+		check.Timeout = check.Interval
+	}
+	if check.Method == "" {
+		check.Method = http.MethodHead
+	}
+	var urls []string
+	for _, dsts := range pathsToDestinations {
+		urls = append(urls, dsts...)
+	}
+	hc := newHealthChecker(urls, check.Interval, httpProbe(check.Method, check.Timeout, check.Status))
+
+	return o.handler(sourceFirstHealthy, func(w http.ResponseWriter, r *http.Request) {
+		if o.serveRoot(w, r, sourceFirstHealthy, fallback) {
+			return
+		}
+		for _, dst := range pathsToDestinations[r.URL.Path] {
+			if !hc.healthy(dst) {
+				continue
+			}
+			if o.blank(w, r, sourceFirstHealthy, dst, fallback) {
+				return
+			}
+			o.redirect(w, r, sourceFirstHealthy, dst, http.StatusFound, fallback)
+			return
+		}
+		fallback.ServeHTTP(w, r)
+	}), hc.stop
+}
+
+// probeFunc reports whether the destination url is up.
+type probeFunc func(url string) bool
+
+// headProbe returns a probeFunc issuing a HEAD request that times out
+// after timeout. Any response below 500 counts as up.
+func headProbe(timeout time.Duration) probeFunc {
+	return httpProbe(http.MethodHead, timeout, 0)
+}
+
+// httpProbe returns a probeFunc issuing a request with method that
+// times out after timeout. A response with status counts as up, or any
+// response below 500 if status is 0.
+func httpProbe(method string, timeout time.Duration, status int) probeFunc {
+	client := &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return func(url string) bool {
+		req, err := http.NewRequest(method, url, nil)
+		if err != nil {
+			return false
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		if status != 0 {
+			return resp.StatusCode == status
+		}
+		return resp.StatusCode < http.StatusInternalServerError
+	}
+}
+
+// healthChecker probes a fixed set of destination URLs in the
+// background and keeps the latest result of each.
+type healthChecker struct {
+	down     map[string]*int32 // 1 if the last probe failed
+	probe    probeFunc
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newHealthChecker(urls []string, interval time.Duration, probe probeFunc) *healthChecker {
+	hc := &healthChecker{
+		down:  make(map[string]*int32, len(urls)),
+		probe: probe,
+		done:  make(chan struct{}),
+	}
+	for _, u := range urls {
+		hc.down[u] = new(int32)
+	}
+	go hc.run(interval)
+	return hc
+}
+
+func (hc *healthChecker) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		hc.probeAll()
+		select {
+		case <-ticker.C:
+		case <-hc.done:
+			return
+		}
+	}
+}
+
+func (hc *healthChecker) probeAll() {
+	var wg sync.WaitGroup
+	for u, down := range hc.down {
+		wg.Add(1)
+		go func(u string, down *int32) {
+			defer wg.Done()
+			if hc.probe(u) {
+				atomic.StoreInt32(down, 0)
+			} else {
+				atomic.StoreInt32(down, 1)
+			}
+		}(u, down)
+	}
+	wg.Wait()
+}
+
+// healthy reports whether url passed its last probe. URLs that aren't
+// checked are always healthy.
+func (hc *healthChecker) healthy(url string) bool {
+	down, ok := hc.down[url]
+	return !ok || atomic.LoadInt32(down) == 0
+}
+
+func (hc *healthChecker) stop() {
+	hc.stopOnce.Do(func() { close(hc.done) })
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"sync"
+)
+
+// errLookupPanicked is what waiters get if the lookup they wait for
+// panics.
+var errLookupPanicked = errors.New("lookup panicked")
+
+// lookupGroup coalesces concurrent database lookups of the same path:
+// while one is in flight, later requests for the path wait for its
+// result instead of querying again, so a burst of requests for a hot
+// path costs one query. Nothing is kept once the lookup returns, so a
+// failed lookup, or a miss, is only shared with the requests that were
+// already waiting for it. The zero lookupGroup is ready to use.
+type lookupGroup struct {
+	mu    sync.Mutex
+	calls map[string]*lookupCall
+}
+
+type lookupCall struct {
+	done chan struct{}
+	dst  urlmap
+	err  error
+}
+
+// do returns the result of lookup for path, running it unless a lookup
+// of path is already in flight.
+func (g *lookupGroup) do(path string, lookup func() (urlmap, error)) (urlmap, error) {
+	g.mu.Lock()
+	if call, ok := g.calls[path]; ok {
+		g.mu.Unlock()
+		<-call.done
+		return call.dst, call.err
+	}
+	if g.calls == nil {
+		g.calls = make(map[string]*lookupCall)
+	}
+	call := &lookupCall{done: make(chan struct{}), err: errLookupPanicked}
+	g.calls[path] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, path)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	call.dst, call.err = lookup()
+	return call.dst, call.err
+}
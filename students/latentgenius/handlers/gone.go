@@ -0,0 +1,25 @@
+package handlers
+
+import "net/http"
+
+// GoneURL is a special destination value marking a path as permanently
+// retired. Instead of redirecting, or falling through to the 404
+// fallback, the handlers answer such paths with 410 Gone, which tells
+// crawlers to drop the link.
+//
+// It can be used as the url of any mapping, in a JSON file or in the
+// database:
+//
+//	{
+//		"/spring-sale": "gone:"
+//	}
+//
+// In YAML, an entry may set gone instead of url:
+//
+//   - path: /spring-sale
+//     gone: true
+const GoneURL = "gone:"
+
+func gone(w http.ResponseWriter) {
+	http.Error(w, "410 gone", http.StatusGone)
+}
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"log"
 	"net/http"
 
@@ -17,15 +16,68 @@ import (
 // that each key in the map points to, in string format).
 // If the path is not provided in the map, then the fallback
 // http.Handler will be called instead.
-func MapHandler(pathsToUrls map[string]string, fallback http.Handler) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		path, ok := pathsToUrls[r.URL.Path]
+//
+// All the source handlers accept a nil fallback, in which case
+// http.NotFoundHandler() is used.
+//
+// The handler serves a copy of pathsToUrls, made when it is built.
+func MapHandler(pathsToUrls map[string]string, fallback http.Handler, opts ...Option) http.HandlerFunc {
+	o := newOptions(opts)
+	store := NewMapStore(pathsToUrls)
+	if o.needsMapping() {
+		return mapHandler(sourceMap, store.links, fallback, o)
+	}
+	return storeHandler(sourceMap, store, http.StatusFound, fallback, o)
+}
+
+// needsMapping reports whether o sets options that need the whole
+// mapping up front, which a Store can't provide.
+func (o *options) needsMapping() bool {
+	return o.canonicalize != nil || o.parentFallback || o.versionParam != ""
+}
+
+func mapHandler(source string, pathsToUrls map[string]string, fallback http.Handler, o *options) http.HandlerFunc {
+	return mappingHandler(source, &mapping{urls: pathsToUrls}, fallback, o)
+}
+
+// mappingHandler is the handler behind all the map-based sources.
+func mappingHandler(source string, m *mapping, fallback http.Handler, o *options) http.HandlerFunc {
+	fallback = o.missFallback(source, fallback)
+	if o.canonicalize != nil {
+		m = m.withCanonical(source, o.canonicalize)
+	}
+	var version string
+	if o.versionParam != "" {
+		version = m.version()
+	}
+	return o.handler(source, func(w http.ResponseWriter, r *http.Request) {
+		if o.serveRoot(w, r, source, fallback) {
+			return
+		}
+		key, path, code, ok := m.match(r)
+		if !ok && o.parentFallback {
+			key, path, code, ok = m.ancestor(r.URL.Path)
+		}
 		if ok {
-			http.Redirect(w, r, path, http.StatusFound)
+			if o.blank(w, r, source, path, fallback) {
+				return
+			}
+			if !m.limits.allow(key) {
+				tooManyRequests(w)
+				return
+			}
+			if msg, ok := m.deprecated[key]; ok {
+				warnDeprecated(w, r, source, msg)
+			}
+			if version != "" && path != GoneURL && !blocked(path) {
+				path = setQueryParam(path, o.versionParam, version)
+			}
+			o.setMatchedRule(w, key)
+			o.redirect(w, o.withShortlinkPath(r, key), source, path, code, fallback)
 		} else {
 			fallback.ServeHTTP(w, r)
 		}
-	}
+	})
 }
 
 // YAMLHandler will parse the provided YAML and then return
@@ -36,21 +88,80 @@ func MapHandler(pathsToUrls map[string]string, fallback http.Handler) http.Handl
 //
 // YAML is expected to be in the format:
 //
-//     - path: /some-path
-//       url: https://www.some-url.com/demo
+//   - path: /some-path
+//     url: https://www.some-url.com/demo
+//
+// An entry with gone: true instead of a url marks a retired path; see
+// GoneURL. Likewise legal: true, with an optional authority URL, marks
+// a path blocked for legal reasons; see LegalPrefix. A url of the form
+// "@/other-path" (quoted, since @ can't start a plain YAML scalar) is an
+// alias resolving to whatever /other-path points to.
+//
+// Instead of a url, an entry may give a base and a suffix, which are
+// joined into the url. Together with YAML anchors and merge keys this
+// avoids repeating a common base URL:
+//
+//   - &docs
+//     path: /docs
+//     base: https://docs.some-url.com/v2/
+//     suffix: index.html
+//   - <<: *docs
+//     path: /install
+//     suffix: install.html
+//
+// An entry with ignore_case: true matches its path case-insensitively,
+// for forgiving vanity links; other entries match exactly, as needed
+// for e.g. signed tokens. An exact match always takes precedence, so
+// /Promo with ignore_case set answers /promo only if no entry has the
+// path /promo. Two ignore_case entries may not differ only in case.
+//
+// An entry may also set status to a redirect status such as 301; the
+// default is 302 Found. With 307 or 308, clients repeat the request
+// against the destination with the same method and body, so a POST can
+// be forwarded; the handlers never read the request body.
+//
+// An entry with rate_limit: N is limited to N redirects per second,
+// with bursts of up to N; requests beyond that get 429 Too Many
+// Requests. Entries without a rate_limit are unrestricted.
+//
+// An entry with deprecated: true still redirects, but with a header
+// such as Warning: 299 - "this link is deprecated and will be removed",
+// to give integrators notice before the link goes; an entry's
+// deprecation_message replaces the default text. Each use of a
+// deprecated entry is logged.
+//
+// Entries match the request path alone, whatever the query, and the
+// query isn't carried over to the destination. An entry with strict:
+// true instead matches only if the path and query of the request
+// together equal its path exactly, as in path: /promo?x=1; a strict
+// entry without a query matches only requests without one. Strict
+// entries are looked up first, so /promo?x=1 prefers a strict
+// /promo?x=1 entry over a plain /promo one.
 //
 // The only errors that can be returned all related to having
-// invalid YAML data.
+// invalid YAML data, invalid statuses or aliases that can't be
+// resolved.
 //
 // See MapHandler to create a similar http.HandlerFunc via
 // a mapping of paths to urls.
-func YAMLHandler(yaml []byte, fallback http.Handler) (http.HandlerFunc, error) {
-	parsedYaml, err := parseYAML(yaml)
+func YAMLHandler(yaml []byte, fallback http.Handler, opts ...Option) (http.HandlerFunc, error) {
+	o := newOptions(opts)
+	parse := parseYAML
+	if o.strictYAML {
+		parse = parseYAMLStrict
+	}
+	parsedYaml, err := parse(yaml)
 	if err != nil {
 		return nil, err
 	}
-	pathMap := buildMap(parsedYaml)
-	return MapHandler(pathMap, fallback), nil
+	if err := o.checkEntries(len(parsedYaml)); err != nil {
+		return nil, err
+	}
+	m, err := buildMapping(parsedYaml)
+	if err != nil {
+		return nil, err
+	}
+	return mappingHandler(sourceYAML, m, fallback, o), nil
 }
 
 // JSONHandler will parse the provided JSON and then return
@@ -61,52 +172,63 @@ func YAMLHandler(yaml []byte, fallback http.Handler) (http.HandlerFunc, error) {
 //
 // JSON is expected to be in the format:
 //
-//		{
-//			"/some-path":"https://www.some-url.com/demo"
-//		}
+//	{
+//		"/some-path":"https://www.some-url.com/demo"
+//	}
 //
 // The only errors that can be returned all related to having
 // invalid JSON data.
 //
 // See MapHandler to create a similar http.HandlerFunc via
 // a mapping of paths to urls.
-func JSONHandler(jsonData []byte, fallback http.Handler) (http.HandlerFunc, error) {
+func JSONHandler(jsonData []byte, fallback http.Handler, opts ...Option) (http.HandlerFunc, error) {
+	o := newOptions(opts)
 	parsedJSON, err := parseJSON(jsonData)
 	if err != nil {
 		return nil, err
 	}
-	return MapHandler(parsedJSON, fallback), nil
+	if err := o.checkEntries(len(parsedJSON)); err != nil {
+		return nil, err
+	}
+	return mapHandler(sourceJSON, parsedJSON, fallback, o), nil
+}
+
+// urlmap is the gorm model of a row in the urlmaps table.
+type urlmap struct {
+	Shortpath string `gorm:"not null;unique_index"`
+	URL       string `gorm:"not null"`
 }
 
 // DBHandler will return an http.HandlerFunc that queries the database for the
-// request URL and redirects as necessary
-func DBHandler(db *gorm.DB, fallback http.Handler) (http.HandlerFunc, error) {
-	type urlmap struct {
-		Shortpath string `gorm:"not null;unique_index"`
-		URL       string `gorm:"not null"`
-	}
-	if err := db.AutoMigrate(&urlmap{}).Error; err != nil {
+// request URL and redirects as necessary. It is StoreHandler over a
+// DBStore of db, redirecting with 301 Moved Permanently.
+func DBHandler(db *gorm.DB, fallback http.Handler, opts ...Option) (http.HandlerFunc, error) {
+	store, err := NewDBStore(db)
+	if err != nil {
 		log.Println("Gorm error: ", err)
+		store = &DBStore{db: db}
 	}
+	return storeHandler(sourceDB, store, http.StatusMovedPermanently, fallback, newOptions(opts)), nil
+}
 
-	return func(w http.ResponseWriter, r *http.Request) {
-		urlMap := urlmap{
-			Shortpath: r.URL.Path,
-		}
-		var dst urlmap
-		err := db.Where(urlMap).First(&dst).Error
-		if err != nil {
-			if err == gorm.ErrRecordNotFound {
-				fallback.ServeHTTP(w, r)
-			} else {
-				fmt.Fprintf(w, "Unexpected error: %s", err)
-			}
-			return
-		}
-		http.Redirect(w, r, dst.URL, http.StatusMovedPermanently)
+// lookupURL returns the row of the urlmaps table for path.
+func lookupURL(db *gorm.DB, path string) (urlmap, error) {
+	urlMap := urlmap{
+		Shortpath: path,
+	}
+	var dst urlmap
+	err := db.Where(urlMap).First(&dst).Error
+	return dst, err
+}
 
-	}, nil
+// orNotFound returns fallback, or http.NotFoundHandler() if it is nil.
+func orNotFound(fallback http.Handler) http.Handler {
+	if fallback == nil {
+		return http.NotFoundHandler()
+	}
+	return fallback
 }
+
 func parseYAML(yaml []byte) (dst []map[string]string, err error) {
 	if err = yamlV2.Unmarshal(yaml, &dst); err != nil {
 		return nil, err
@@ -120,11 +242,26 @@ func parseJSON(jsonData []byte) (dst map[string]string, err error) {
 	}
 	return dst, nil
 }
-func buildMap(parsedYaml []map[string]string) map[string]string {
+func buildMap(parsedYaml []map[string]string) (map[string]string, error) {
 	mergedMap := make(map[string]string)
 	for _, entry := range parsedYaml {
 		key := entry["path"]
-		mergedMap[key] = entry["url"]
+		if entry["gone"] == "true" {
+			mergedMap[key] = GoneURL
+			continue
+		}
+		if entry["legal"] == "true" {
+			mergedMap[key] = LegalPrefix + entry["authority"]
+			continue
+		}
+		url, ok := entry["url"]
+		if !ok {
+			url = entry["base"] + entry["suffix"]
+		}
+		mergedMap[key] = url
+	}
+	if err := resolveAliases(mergedMap); err != nil {
+		return nil, err
 	}
-	return mergedMap
+	return mergedMap, nil
 }
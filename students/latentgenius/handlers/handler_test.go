@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gophercises/urlshort/students/latentgenius/handlers/handlerstest"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+// openTestDB opens a fresh sqlite database holding the urlmaps table
+// with pathsToUrls, closed when the test ends.
+func openTestDB(t *testing.T, pathsToUrls map[string]string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open("sqlite3", filepath.Join(t.TempDir(), "urls.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.LogMode(false)
+	if err := db.AutoMigrate(&urlmap{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	for path, url := range pathsToUrls {
+		if err := db.Create(&urlmap{Shortpath: path, URL: url}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestNilFallback(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(t *testing.T) http.Handler
+		code    int
+	}{
+		{"map", func(t *testing.T) http.Handler {
+			return MapHandler(map[string]string{"/hit": "https://example.com/hit"}, nil)
+		}, http.StatusFound},
+		{"yaml", func(t *testing.T) http.Handler {
+			h, err := YAMLHandler([]byte("- path: /hit\n  url: https://example.com/hit\n"), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return h
+		}, http.StatusFound},
+		{"json", func(t *testing.T) http.Handler {
+			h, err := JSONHandler([]byte(`{"/hit": "https://example.com/hit"}`), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return h
+		}, http.StatusFound},
+		{"db", func(t *testing.T) http.Handler {
+			h, err := DBHandler(openTestDB(t, map[string]string{"/hit": "https://example.com/hit"}), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return h
+		}, http.StatusMovedPermanently},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := tt.handler(t)
+			status, location, _ := handlerstest.ProbeHandler(h, "GET", "/hit")
+			if status != tt.code || location != "https://example.com/hit" {
+				t.Errorf("GET /hit = %d %q, want %d https://example.com/hit", status, location, tt.code)
+			}
+			status, _, _ = handlerstest.ProbeHandler(h, "GET", "/miss")
+			if status != http.StatusNotFound {
+				t.Errorf("GET /miss = %d, want %d", status, http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func TestYAMLHandlerPreservesPOST(t *testing.T) {
+	dest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(body)))
+	}))
+	defer dest.Close()
+
+	yaml := "- path: /temporary\n  url: " + dest.URL + "/api/temporary\n  status: 307\n" +
+		"- path: /permanent\n  url: " + dest.URL + "/api/permanent\n  status: 308\n"
+	h, err := YAMLHandler([]byte(yaml), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	short := httptest.NewServer(h)
+	defer short.Close()
+
+	for _, name := range []string{"temporary", "permanent"} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := http.Post(short.URL+"/"+name, "text/plain", strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			got, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "POST /api/" + name + " payload"; string(got) != want {
+				t.Errorf("destination got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMapHandlerCopiesMap(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"store", nil},
+		{"mapping", []Option{WithParentFallback()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pathsToUrls := map[string]string{"/a": "https://example.com/a"}
+			h := MapHandler(pathsToUrls, nil, tt.opts...)
+			pathsToUrls["/a"] = "https://example.com/changed"
+			pathsToUrls["/b"] = "https://example.com/b"
+
+			if _, location, _ := handlerstest.ProbeHandler(h, "GET", "/a"); location != "https://example.com/a" {
+				t.Errorf("GET /a redirected to %q, want https://example.com/a", location)
+			}
+			if status, _, _ := handlerstest.ProbeHandler(h, "GET", "/b"); status != http.StatusNotFound {
+				t.Errorf("GET /b = %d, want %d", status, http.StatusNotFound)
+			}
+		})
+	}
+}
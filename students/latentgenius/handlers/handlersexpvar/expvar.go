@@ -0,0 +1,21 @@
+// Package handlersexpvar publishes the activity counters of package
+// handlers with expvar, for a zero-dependency view of the shortener on
+// /debug/vars. It is separate from package handlers because importing
+// expvar registers /debug/vars on http.DefaultServeMux, which not every
+// program wants.
+package handlersexpvar
+
+import (
+	"expvar"
+
+	"github.com/gophercises/urlshort/students/latentgenius/handlers"
+)
+
+// Publish publishes handlers.ReadCounters as an expvar map called name,
+// with the keys requests, redirects and fallbacks. Like expvar.Publish,
+// it panics if name is already in use.
+func Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return handlers.ReadCounters()
+	}))
+}
@@ -0,0 +1,24 @@
+// Package handlerstest provides utilities for testing the redirect
+// handlers of package handlers.
+package handlerstest
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+// ProbeHandler sends h a request with method for target, a path or an
+// absolute URL as accepted by httptest.NewRequest, and returns the
+// status, the Location header and the body of the response, e.g.
+//
+//	status, location, _ := handlerstest.ProbeHandler(h, "GET", "/promo")
+//	if status != http.StatusFound || location != "https://example.com/spring" {
+//		t.Errorf("GET /promo = %d %q", status, location)
+//	}
+//
+// It panics, as httptest.NewRequest does, if target can't be parsed.
+func ProbeHandler(h http.Handler, method, target string) (status int, location string, body []byte) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec.Code, rec.Header().Get("Location"), rec.Body.Bytes()
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// base62 is the alphabet of short codes.
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// DefaultCodeLength is the short code length used by HashCode when
+// given a non-positive length.
+const DefaultCodeLength = 7
+
+// HashCode derives a stable base62 short code of the given length from
+// the SHA-256 hash of url, so re-importing the same URL always yields
+// the same code. Lengths above 43, the most a SHA-256 sum provides, are
+// capped; a non-positive length means DefaultCodeLength.
+//
+// Different URLs can collide. At the default length there are 62^7,
+// about 3.5e12, codes, so among n URLs the chance of any collision is
+// roughly n²/7e12: about 0.14% for 100k URLs and 14% for 1M. Callers
+// storing codes should check for a collision and, on one, retry with a
+// longer code, as CreateDeterministic does; the shorter code is always
+// a prefix of the longer one.
+func HashCode(url string, length int) string {
+	code, _ := HashCodeAlphabet(url, length, base62)
+	return code
+}
+
+// CreateDeterministic links longURL, an absolute http or https URL,
+// in store under the path of its HashCode, and returns the path. A
+// path already linked to longURL counts as success, so importing the
+// same URL again doesn't create a duplicate. A path linked to another
+// URL is a collision, resolved by lengthening the code one digit at a
+// time from DefaultCodeLength; since the order is fixed, a re-import
+// ends up on the same code as the first import did, as long as the
+// links before it are unchanged.
+func CreateDeterministic(store Store, longURL string) (string, error) {
+	if err := checkLinkURL(longURL); err != nil {
+		return "", err
+	}
+	for length := DefaultCodeLength; ; length++ {
+		code := HashCode(longURL, length)
+		if len(code) < length {
+			return "", fmt.Errorf("no free hash code for %q", longURL)
+		}
+		path := "/" + code
+		err := store.PutIfAbsent(path, longURL)
+		if err != ErrLinkExists {
+			return path, err
+		}
+		dst, ok, err := store.Get(path)
+		if err != nil {
+			return "", err
+		}
+		if ok && dst == longURL {
+			return path, nil
+		}
+	}
+}
+
+// CrockfordBase32 is Douglas Crockford's base32 alphabet, which leaves
+// out I, L, O and U so codes can't be misread (0/O, 1/l) when printed
+// or read aloud.
+const CrockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// HashCodeAlphabet is HashCode with the digits of the code taken from
+// alphabet instead of base62, e.g. CrockfordBase32. Smaller alphabets
+// give fewer codes of a given length, so they need longer codes for
+// the same collision odds. The length is capped at the number of
+// digits a SHA-256 sum fills in the alphabet. An error is returned if
+// alphabet has fewer than two characters or repeats one.
+func HashCodeAlphabet(url string, length int, alphabet string) (string, error) {
+	digits, err := alphabetDigits(alphabet)
+	if err != nil {
+		return "", err
+	}
+	if length <= 0 {
+		length = DefaultCodeLength
+	}
+	if max := int(math.Ceil(256 / math.Log2(float64(len(digits))))); length > max {
+		length = max
+	}
+	sum := sha256.Sum256([]byte(url))
+	n := new(big.Int).SetBytes(sum[:])
+	radix := big.NewInt(int64(len(digits)))
+	digit := new(big.Int)
+	code := make([]rune, length)
+	for i := range code {
+		n.DivMod(n, radix, digit)
+		code[i] = digits[digit.Int64()]
+	}
+	return string(code), nil
+}
+
+// alphabetDigits returns the digits of alphabet, or an error if it has
+// fewer than two or repeats one.
+func alphabetDigits(alphabet string) ([]rune, error) {
+	digits := []rune(alphabet)
+	if len(digits) < 2 {
+		return nil, fmt.Errorf("alphabet %q has fewer than 2 characters", alphabet)
+	}
+	seen := make(map[rune]bool, len(digits))
+	for _, d := range digits {
+		if seen[d] {
+			return nil, fmt.Errorf("alphabet %q repeats %q", alphabet, d)
+		}
+		seen[d] = true
+	}
+	return digits, nil
+}
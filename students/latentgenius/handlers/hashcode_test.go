@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashCode(t *testing.T) {
+	code := HashCode("https://example.com/", 0)
+	if len(code) != DefaultCodeLength {
+		t.Errorf("HashCode with length 0 = %q, want %d digits", code, DefaultCodeLength)
+	}
+	if again := HashCode("https://example.com/", 0); again != code {
+		t.Errorf("HashCode isn't stable: %q, then %q", code, again)
+	}
+	if longer := HashCode("https://example.com/", 10); !strings.HasPrefix(longer, code) {
+		t.Errorf("HashCode of length 10 = %q, want it to extend %q", longer, code)
+	}
+	if max := HashCode("https://example.com/", 100); len(max) != 43 {
+		t.Errorf("HashCode of length 100 has %d digits, want 43", len(max))
+	}
+}
+
+func TestCreateDeterministic(t *testing.T) {
+	store := NewMapStore(nil)
+	path, err := CreateDeterministic(store, "https://example.com/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/" + HashCode("https://example.com/a", 0); path != want {
+		t.Errorf("CreateDeterministic = %q, want %q", path, want)
+	}
+	again, err := CreateDeterministic(store, "https://example.com/a")
+	if err != nil || again != path {
+		t.Errorf("second CreateDeterministic = %q, %v, want %q", again, err, path)
+	}
+	if links, _ := store.List(); len(links) != 1 {
+		t.Errorf("store holds %d links, want 1", len(links))
+	}
+}
+
+func TestCreateDeterministicCollision(t *testing.T) {
+	const url = "https://example.com/b"
+	short := "/" + HashCode(url, DefaultCodeLength)
+	store := NewMapStore(map[string]string{short: "https://example.com/other"})
+
+	path, err := CreateDeterministic(store, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/" + HashCode(url, DefaultCodeLength+1); path != want {
+		t.Errorf("CreateDeterministic = %q, want the longer %q", path, want)
+	}
+	if dst, _, _ := store.Get(short); dst != "https://example.com/other" {
+		t.Errorf("colliding link now points to %q", dst)
+	}
+	if again, err := CreateDeterministic(store, url); err != nil || again != path {
+		t.Errorf("re-import = %q, %v, want %q", again, err, path)
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const sourceHashRing = "hashring"
+
+// ringReplicas is the number of points each host gets on a HashRing;
+// more points spread keys more evenly.
+const ringReplicas = 100
+
+// HashRing assigns keys to hosts by consistent hashing: each host owns
+// the arcs of a hash ring ending at its points, so adding or removing a
+// host only moves the keys of its own arcs, about 1/n of them. It is
+// safe for concurrent use, including updates while requests are served.
+// The zero HashRing is empty.
+type HashRing struct {
+	mu     sync.RWMutex
+	hosts  map[string]bool
+	points []ringPoint // sorted by hash
+}
+
+type ringPoint struct {
+	hash uint64
+	host string
+}
+
+// NewHashRing returns a HashRing over hosts.
+func NewHashRing(hosts ...string) *HashRing {
+	ring := &HashRing{}
+	ring.SetHosts(hosts...)
+	return ring
+}
+
+// SetHosts replaces the hosts of the ring.
+func (ring *HashRing) SetHosts(hosts ...string) {
+	set := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		set[host] = true
+	}
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	ring.hosts = set
+	ring.rebuild()
+}
+
+// AddHost adds host to the ring.
+func (ring *HashRing) AddHost(host string) {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if ring.hosts == nil {
+		ring.hosts = make(map[string]bool)
+	}
+	ring.hosts[host] = true
+	ring.rebuild()
+}
+
+// RemoveHost removes host from the ring.
+func (ring *HashRing) RemoveHost(host string) {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	delete(ring.hosts, host)
+	ring.rebuild()
+}
+
+// Host returns the host owning key, or "" if the ring is empty.
+func (ring *HashRing) Host(key string) string {
+	h := ringHash(key)
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+	if len(ring.points) == 0 {
+		return ""
+	}
+	i := sort.Search(len(ring.points), func(i int) bool { return ring.points[i].hash >= h })
+	if i == len(ring.points) {
+		i = 0
+	}
+	return ring.points[i].host
+}
+
+// rebuild lays out the points of the current hosts. The caller holds
+// the write lock.
+func (ring *HashRing) rebuild() {
+	points := make([]ringPoint, 0, len(ring.hosts)*ringReplicas)
+	for host := range ring.hosts {
+		for i := 0; i < ringReplicas; i++ {
+			points = append(points, ringPoint{ringHash(host + "#" + strconv.Itoa(i)), host})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash != points[j].hash {
+			return points[i].hash < points[j].hash
+		}
+		return points[i].host < points[j].host
+	})
+	ring.points = points
+}
+
+// ringHash hashes s onto the ring. FNV alone leaves similar strings,
+// such as the points of one host, close together; the splitmix64
+// finalizer spreads them out.
+func ringHash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// HashRingHandler will return an http.HandlerFunc that spreads the
+// paths under prefix across the hosts of ring, so that, say, every
+// /asset/{id} goes to the same one of several sharded asset hosts: the
+// rest of the path after prefix is the key hashed onto the ring, and is
+// appended to the path of the host it lands on, as PrefixHandler does.
+// With
+//
+//	HashRingHandler("/asset/", NewHashRing("https://a1.example/", "https://a2.example/"), nil)
+//
+// /asset/42 redirects to https://a1.example/42 or https://a2.example/42,
+// always the same one while the ring is unchanged. The hosts can be
+// updated at run time through the ring. Paths outside prefix, bare
+// prefix requests and requests while the ring is empty fall through to
+// the fallback http.Handler.
+func HashRingHandler(prefix string, ring *HashRing, fallback http.Handler, opts ...Option) http.HandlerFunc {
+	o := newOptions(opts)
+	fallback = o.missFallback(sourceHashRing, fallback)
+
+	return o.handler(sourceHashRing, func(w http.ResponseWriter, r *http.Request) {
+		if o.serveRoot(w, r, sourceHashRing, fallback) {
+			return
+		}
+		path := r.URL.EscapedPath()
+		key := strings.TrimPrefix(path, prefix)
+		if key == path || key == "" {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		host := ring.Host(key)
+		if host == "" {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		dst, err := appendPath(host, key)
+		if err != nil {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		o.redirect(w, r, sourceHashRing, dst, http.StatusFound, fallback)
+	})
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// HopsHeader is the request and response header carrying the number of
+// redirects a request has gone through across cooperating shorteners.
+const HopsHeader = "X-Redirect-Hops"
+
+// DefaultMaxHops is the hop limit used by WithHopLimit when given a
+// non-positive maximum.
+const DefaultMaxHops = 10
+
+// WithHopLimit makes the handler take part in cross-service loop
+// detection. It reads the inbound hop count from the X-Redirect-Hops
+// request header (a missing or malformed header counts as 0). If the
+// count has reached max, the handler refuses to redirect and answers
+// 508 Loop Detected; otherwise it sets X-Redirect-Hops on the redirect
+// response to the count plus one, for the next shortener in the chain
+// to forward. A max of 0 or less means DefaultMaxHops.
+func WithHopLimit(max int) Option {
+	if max <= 0 {
+		max = DefaultMaxHops
+	}
+	return func(o *options) {
+		o.maxHops = max
+	}
+}
+
+// checkHops enforces the hop limit. It reports whether the redirect may
+// go ahead; if not, it has already answered the request.
+func (o *options) checkHops(w http.ResponseWriter, r *http.Request) bool {
+	if o.maxHops == 0 {
+		return true
+	}
+	hops, err := strconv.Atoi(r.Header.Get(HopsHeader))
+	if err != nil || hops < 0 {
+		hops = 0
+	}
+	if hops >= o.maxHops {
+		http.Error(w, "508 loop detected", http.StatusLoopDetected)
+		return false
+	}
+	w.Header().Set(HopsHeader, strconv.Itoa(hops+1))
+	return true
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// IssuedCodes is the set of every path ever handed out as a short code,
+// kept in a file so it survives restarts. A code that is deleted from
+// the mapping stays issued, so passing Issued to SuggestSlug as (part
+// of) its taken func guarantees a retired code, still printed on a
+// flyer somewhere, is never handed out again for another destination.
+// It is safe for concurrent use.
+//
+// The file holds one path per line and only grows: each issued code
+// costs its length plus a newline on disk, and about as much again in
+// memory, so a million 8-character codes take some 9MB of each. Use
+// Prune to drop codes no reference can still use.
+type IssuedCodes struct {
+	mu     sync.Mutex
+	name   string
+	file   *os.File
+	issued map[string]bool
+}
+
+// OpenIssuedCodes opens the issued codes file name, creating it if it
+// doesn't exist.
+func OpenIssuedCodes(name string) (*IssuedCodes, error) {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	issued := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			issued[line] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return &IssuedCodes{name: name, file: f, issued: issued}, nil
+}
+
+// Issued reports whether path has ever been issued.
+func (c *IssuedCodes) Issued(path string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.issued[path]
+}
+
+// Issue records path as issued, on disk before it returns. It is an
+// error to issue a path twice.
+func (c *IssuedCodes) Issue(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.issued[path] {
+		return fmt.Errorf("code %s was already issued", path)
+	}
+	if _, err := c.file.WriteString(path + "\n"); err != nil {
+		return err
+	}
+	if err := c.file.Sync(); err != nil {
+		return err
+	}
+	c.issued[path] = true
+	return nil
+}
+
+// Prune forgets the issued paths for which dead returns true, making
+// them available again, and rewrites the file without them. A code is
+// only safe to prune once nothing can still refer to it: say, it was
+// retired longer ago than the lifetime of anything it was printed on.
+// Codes still in the mapping must never be pruned.
+func (c *IssuedCodes) Prune(dead func(path string) bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tmp, err := os.CreateTemp(filepath.Dir(c.name), filepath.Base(c.name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	keep := make(map[string]bool, len(c.issued))
+	w := bufio.NewWriter(tmp)
+	for path := range c.issued {
+		if dead(path) {
+			continue
+		}
+		keep[path] = true
+		w.WriteString(path + "\n")
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.name); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(c.name, os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	c.file.Close()
+	c.file = f
+	c.issued = keep
+	return nil
+}
+
+// Close closes the issued codes file.
+func (c *IssuedCodes) Close() error {
+	return c.file.Close()
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// WithRedirectJitter makes the handler wait a random time of up to max
+// before sending each redirect, so a burst of clicks on a viral link
+// reaches its destination spread over max rather than all at once. A
+// request canceled while waiting, say because the client went away, is
+// dropped without a response. The default, zero, sends redirects right
+// away. Keep max small: every redirect is slowed by half of it on
+// average.
+func WithRedirectJitter(max time.Duration) Option {
+	return func(o *options) {
+		o.jitter = max
+	}
+}
+
+// waitJitter waits the jitter before a redirect and reports whether the
+// request is still wanted.
+func (o *options) waitJitter(r *http.Request) bool {
+	if o.jitter <= 0 {
+		return true
+	}
+	t := time.NewTimer(time.Duration(rand.Int63n(int64(o.jitter))))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// JSONCHandler behaves like JSONHandler, but accepts JSON annotated with
+// // line comments and /* block */ comments, so humans can document the
+// entries:
+//
+//	{
+//		// landing page for the spring campaign
+//		"/promo": "https://www.some-url.com/spring"
+//	}
+//
+// Comment markers inside string values, such as the // in https://,
+// are left alone. Syntax errors report the line and column in the
+// original, commented input.
+func JSONCHandler(jsoncData []byte, fallback http.Handler, opts ...Option) (http.HandlerFunc, error) {
+	o := newOptions(opts)
+	parsedJSON, err := parseJSONC(jsoncData)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.checkEntries(len(parsedJSON)); err != nil {
+		return nil, err
+	}
+	return mapHandler(sourceJSON, parsedJSON, fallback, o), nil
+}
+
+func parseJSONC(jsoncData []byte) (map[string]string, error) {
+	stripped, err := stripJSONComments(jsoncData)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := parseJSON(stripped)
+	if err != nil {
+		if serr, ok := err.(*json.SyntaxError); ok {
+			line, col := position(jsoncData, serr.Offset)
+			return nil, fmt.Errorf("jsonc: line %d, column %d: %v", line, col, err)
+		}
+		return nil, err
+	}
+	return dst, nil
+}
+
+// stripJSONComments blanks out comments with spaces, keeping newlines,
+// so byte offsets in the result match those in the input.
+func stripJSONComments(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	copy(out, data)
+	inString := false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		if c == '"' {
+			inString = true
+			continue
+		}
+		if c != '/' || i+1 >= len(out) {
+			continue
+		}
+		switch out[i+1] {
+		case '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case '*':
+			start := i
+			out[i], out[i+1] = ' ', ' '
+			for i += 2; ; i++ {
+				if i+1 >= len(out) {
+					line, col := position(data, int64(start))
+					return nil, fmt.Errorf("jsonc: line %d, column %d: unterminated block comment", line, col)
+				}
+				if out[i] == '*' && out[i+1] == '/' {
+					out[i], out[i+1] = ' ', ' '
+					i++
+					break
+				}
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// position converts a byte offset in data to a 1-based line and column.
+func position(data []byte, offset int64) (line, col int) {
+	line, col = 1, 1
+	for i := int64(0); i < offset && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
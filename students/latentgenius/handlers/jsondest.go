@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// WithJSONDestination makes the handler answer the requests for which
+// match returns true with 200 OK and the destination in a JSON body,
+//
+//	{"url":"https://www.some-url.com/demo"}
+//
+// instead of a redirect, for clients such as mobile apps that can't
+// follow some redirects but can open a URL themselves. Everyone else
+// still gets the redirect. match is called for every matched request
+// and must be safe for concurrent use; UserAgentContains builds the
+// common one. Since the response then depends on the request, put the
+// headers match looks at in a Vary header if responses may be cached.
+func WithJSONDestination(match func(r *http.Request) bool) Option {
+	return func(o *options) {
+		o.jsonDestination = match
+	}
+}
+
+// UserAgentContains returns a predicate for WithJSONDestination matching
+// requests whose User-Agent contains substr.
+func UserAgentContains(substr string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		return strings.Contains(r.UserAgent(), substr)
+	}
+}
+
+func writeJSONDestination(w http.ResponseWriter, url string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		URL string `json:"url"`
+	}{url})
+}
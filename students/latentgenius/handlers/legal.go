@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// LegalPrefix starts a special destination value marking a path as
+// blocked for legal reasons, e.g. by a takedown order. Such paths are
+// answered with 451 Unavailable For Legal Reasons (RFC 7725) rather than
+// redirected. The rest of the value, if any, is the URL of the blocking
+// authority, sent in a Link header with rel="blocked-by".
+//
+// It can be used as the url of any mapping, in a JSON file or in the
+// database:
+//
+//	{
+//		"/leaked": "legal:https://authority.example/orders/1234"
+//	}
+//
+// In YAML, an entry may set legal instead of url, and optionally an
+// authority:
+//
+//   - path: /leaked
+//     legal: true
+//     authority: https://authority.example/orders/1234
+const LegalPrefix = "legal:"
+
+// blocked reports whether dst marks a legally blocked path.
+func blocked(dst string) bool {
+	return strings.HasPrefix(dst, LegalPrefix)
+}
+
+func unavailableForLegalReasons(w http.ResponseWriter, dst string) {
+	if authority := strings.TrimPrefix(dst, LegalPrefix); authority != "" {
+		w.Header().Set("Link", "<"+authority+`>; rel="blocked-by"`)
+	}
+	http.Error(w, "451 unavailable for legal reasons", http.StatusUnavailableForLegalReasons)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LoadFile reads the mapping file at path and returns its paths and
+// destinations, ready for MapHandler. The format is chosen by the file
+// extension, as for EmbedHandler; files with another extension are
+// sniffed instead: a first non-blank byte of { means (commented) JSON,
+// and [ means a YAML list of entries, of which a JSON array is a
+// special case. Other files are rejected. Errors name the file.
+//
+// Only paths and destinations are returned: per-entry settings such as
+// status or rate_limit need YAMLHandler.
+func LoadFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := parseFile(path, data)
+	if err != nil {
+		return nil, err
+	}
+	return m.urls, nil
+}
+
+// LoadDir loads every file in dir, as LoadFile does, and merges them
+// into one mapping. Subdirectories and hidden files are skipped. A path
+// defined by two files is an error naming both, rather than one file
+// silently winning over the other.
+func LoadDir(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]string)
+	definedIn := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		name := filepath.Join(dir, entry.Name())
+		pathMap, err := LoadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		for key, url := range pathMap {
+			if other, ok := definedIn[key]; ok {
+				return nil, fmt.Errorf("%s: path %q is also defined in %s", name, key, other)
+			}
+			definedIn[key] = name
+			merged[key] = url
+		}
+	}
+	return merged, nil
+}
+
+// parseFile parses data read from the file name, by extension if it
+// has a known one and by content otherwise.
+func parseFile(name string, data []byte) (*mapping, error) {
+	switch path.Ext(name) {
+	case ".yaml", ".yml", ".json", ".jsonc", ".txt":
+		return parseByExtension(name, data)
+	}
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("%s: unknown mapping format", name)
+	}
+	switch trimmed[0] {
+	case '{':
+		pathMap, err := parseJSONC(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		return &mapping{urls: pathMap}, nil
+	case '[':
+		parsedYaml, err := parseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		m, err := buildMapping(parsedYaml)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("%s: unknown mapping format", name)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files, by name, into a new temporary directory and
+// returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"links.yaml":  "- path: /y\n  url: https://example.com/y\n",
+		"links.json":  `{"/j": "https://example.com/j"}`,
+		"links.jsonc": "{\n  // commented\n  \"/c\": \"https://example.com/c\"\n}",
+		"sniffed":     `{"/s": "https://example.com/s"}`,
+		"unknown":     "hello",
+	})
+	tests := []struct {
+		name string
+		want map[string]string
+	}{
+		{"links.yaml", map[string]string{"/y": "https://example.com/y"}},
+		{"links.json", map[string]string{"/j": "https://example.com/j"}},
+		{"links.jsonc", map[string]string{"/c": "https://example.com/c"}},
+		{"sniffed", map[string]string{"/s": "https://example.com/s"}},
+	}
+	for _, tt := range tests {
+		got, err := LoadFile(filepath.Join(dir, tt.name))
+		if err != nil {
+			t.Errorf("LoadFile(%s): %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LoadFile(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if _, err := LoadFile(filepath.Join(dir, "unknown")); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("LoadFile(unknown) = %v, want an error naming the file", err)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.json":  `{"/a": "https://example.com/a"}`,
+		"b.json":  `{"/b": "https://example.com/b"}`,
+		".hidden": "not a mapping",
+	})
+	got, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"/a": "https://example.com/a", "/b": "https://example.com/b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadDir = %v, want %v", got, want)
+	}
+
+	dup := writeFiles(t, map[string]string{
+		"a.json": `{"/a": "https://example.com/a"}`,
+		"b.json": `{"/a": "https://example.com/other"}`,
+	})
+	if _, err := LoadDir(dup); err == nil || !strings.Contains(err.Error(), "also defined in") {
+		t.Errorf("LoadDir with a duplicate path = %v, want an error naming both files", err)
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+var (
+	maintenance     int32        // 1 while maintenance mode is on
+	maintenancePage atomic.Value // of maintenancePageHolder
+)
+
+// maintenancePageHolder wraps the maintenance page so atomic.Value
+// always stores the same concrete type.
+type maintenancePageHolder struct {
+	h http.Handler
+}
+
+// SetMaintenance turns maintenance mode on or off, at any time and from
+// any goroutine. While it is on, every source handler answers every
+// request with the maintenance page instead of resolving it; redirects
+// resume as soon as it is turned off. Mappings stay loaded either way.
+func SetMaintenance(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&maintenance, v)
+}
+
+// SetMaintenancePage sets the handler serving requests during
+// maintenance. A nil handler restores the default, which answers 503
+// Service Unavailable. The page should set a 503 status itself, so
+// clients and crawlers know the outage is temporary.
+func SetMaintenancePage(h http.Handler) {
+	maintenancePage.Store(maintenancePageHolder{h})
+}
+
+// serveMaintenance answers the request with the maintenance page if
+// maintenance mode is on, and reports whether it did.
+func serveMaintenance(w http.ResponseWriter, r *http.Request) bool {
+	if atomic.LoadInt32(&maintenance) == 0 {
+		return false
+	}
+	if p, ok := maintenancePage.Load().(maintenancePageHolder); ok && p.h != nil {
+		p.h.ServeHTTP(w, r)
+		return true
+	}
+	http.Error(w, "503 service unavailable: down for maintenance", http.StatusServiceUnavailable)
+	return true
+}
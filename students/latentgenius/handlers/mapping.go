@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mapping is the lookup table behind the map-based handlers.
+type mapping struct {
+	urls       map[string]string // path -> destination
+	codes      map[string]int    // path -> redirect status, where not 302
+	folded     map[string]string // lowercased path -> path, for ignore_case entries
+	limits     *pathLimiter      // nil if no entry has a rate_limit
+	strict     map[string]bool   // paths of the strict entries
+	deprecated map[string]string // path -> warning, for deprecated entries
+	// canon indexes the paths by their canonical form, when a
+	// canonicalize hook is set.
+	canon        map[string]string
+	canonicalize func(string) string
+}
+
+// buildMapping builds the mapping of entries in the shape produced by
+// parseYAML: the destinations as built by buildMap, plus the per-entry
+// status, ignore_case and rate_limit settings.
+func buildMapping(entries []map[string]string) (*mapping, error) {
+	urls, err := buildMap(entries)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := buildCodes(entries)
+	if err != nil {
+		return nil, err
+	}
+	folded, err := buildFolded(entries)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := buildLimits(entries)
+	if err != nil {
+		return nil, err
+	}
+	return &mapping{
+		urls:       urls,
+		codes:      codes,
+		folded:     folded,
+		limits:     limits,
+		strict:     buildStrict(entries),
+		deprecated: buildDeprecated(entries),
+	}, nil
+}
+
+// match looks up the request as lookup does, honouring strict entries:
+// those match only if the request's path and query together equal the
+// entry's path, so /promo?x=1 matches a strict /promo?x=1 entry but not
+// a strict /promo one.
+func (m *mapping) match(r *http.Request) (key, dst string, code int, ok bool) {
+	if len(m.strict) == 0 {
+		return m.lookup(r.URL.Path)
+	}
+	if r.URL.RawQuery != "" {
+		if key, dst, code, ok = m.lookup(r.URL.Path + "?" + r.URL.RawQuery); ok && m.strict[key] {
+			return key, dst, code, ok
+		}
+	}
+	key, dst, code, ok = m.lookup(r.URL.Path)
+	if ok && m.strict[key] && r.URL.RawQuery != "" {
+		return "", "", 0, false
+	}
+	return key, dst, code, ok
+}
+
+// lookup returns the mapped path matching path, with its destination
+// and redirect status. Exact matches take precedence over
+// case-insensitive ones, and those over canonical ones.
+func (m *mapping) lookup(path string) (key, dst string, code int, ok bool) {
+	dst, ok = m.urls[path]
+	if !ok && len(m.folded) > 0 {
+		if p, found := m.folded[strings.ToLower(path)]; found {
+			path = p
+			dst, ok = m.urls[p]
+		}
+	}
+	if !ok && m.canonicalize != nil {
+		if p, found := m.canon[m.canonicalize(path)]; found {
+			path = p
+			dst, ok = m.urls[p]
+		}
+	}
+	if !ok {
+		return "", "", 0, false
+	}
+	code, ok = m.codes[path]
+	if !ok {
+		code = http.StatusFound
+	}
+	return path, dst, code, true
+}
+
+// buildCodes collects the per-path redirect statuses of the entries that
+// set one.
+func buildCodes(entries []map[string]string) (map[string]int, error) {
+	codes := make(map[string]int)
+	for _, entry := range entries {
+		s, ok := entry["status"]
+		if !ok {
+			continue
+		}
+		code, err := strconv.Atoi(s)
+		if err != nil || !isRedirectCode(code) {
+			return nil, fmt.Errorf("path %s: invalid redirect status %q", entry["path"], s)
+		}
+		codes[entry["path"]] = code
+	}
+	return codes, nil
+}
+
+func isRedirectCode(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// buildStrict collects the paths of the entries with strict: true.
+func buildStrict(entries []map[string]string) map[string]bool {
+	strict := make(map[string]bool)
+	for _, entry := range entries {
+		if entry["strict"] == "true" {
+			strict[entry["path"]] = true
+		}
+	}
+	return strict
+}
+
+// buildFolded indexes the paths of the ignore_case entries by their
+// lowercased form. It is an error for two of them to differ only in
+// case, since a request could then match either.
+func buildFolded(entries []map[string]string) (map[string]string, error) {
+	folded := make(map[string]string)
+	for _, entry := range entries {
+		if entry["ignore_case"] != "true" {
+			continue
+		}
+		path := entry["path"]
+		key := strings.ToLower(path)
+		if other, ok := folded[key]; ok && other != path {
+			pair := []string{other, path}
+			sort.Strings(pair)
+			return nil, fmt.Errorf("paths %s and %s are equal ignoring case", pair[0], pair[1])
+		}
+		folded[key] = path
+	}
+	return folded, nil
+}
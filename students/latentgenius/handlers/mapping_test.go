@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gophercises/urlshort/students/latentgenius/handlers/handlerstest"
+)
+
+func TestYAMLEntrySettings(t *testing.T) {
+	h, err := YAMLHandler([]byte(`
+- path: /moved
+  url: https://example.com/moved
+  status: 301
+- path: /Promo
+  url: https://example.com/promo
+  ignore_case: true
+- path: /exact
+  url: https://example.com/exact
+- path: /strict?x=1
+  url: https://example.com/strict
+  strict: true
+- path: /plain
+  url: https://example.com/plain
+- path: /plain?x=1
+  url: https://example.com/plain-x
+  strict: true
+- path: /retired
+  gone: true
+- path: /blocked
+  legal: true
+`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		target   string
+		status   int
+		location string
+	}{
+		{"/moved", http.StatusMovedPermanently, "https://example.com/moved"},
+		{"/promo", http.StatusFound, "https://example.com/promo"},
+		{"/PROMO", http.StatusFound, "https://example.com/promo"},
+		{"/Exact", http.StatusNotFound, ""},
+		{"/strict?x=1", http.StatusFound, "https://example.com/strict"},
+		{"/strict", http.StatusNotFound, ""},
+		{"/strict?x=2", http.StatusNotFound, ""},
+		{"/plain?y=1", http.StatusFound, "https://example.com/plain"},
+		{"/plain?x=1", http.StatusFound, "https://example.com/plain-x"},
+		{"/retired", http.StatusGone, ""},
+		{"/blocked", http.StatusUnavailableForLegalReasons, ""},
+	}
+	for _, tt := range tests {
+		status, location, _ := handlerstest.ProbeHandler(h, "GET", tt.target)
+		if status != tt.status || location != tt.location {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.target, status, location, tt.status, tt.location)
+		}
+	}
+}
+
+func TestYAMLRateLimit(t *testing.T) {
+	h, err := YAMLHandler([]byte("- path: /hot\n  url: https://example.com/\n  rate_limit: 2\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var codes []int
+	for i := 0; i < 3; i++ {
+		status, _, _ := handlerstest.ProbeHandler(h, "GET", "/hot")
+		codes = append(codes, status)
+	}
+	if codes[0] != http.StatusFound || codes[1] != http.StatusFound || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("statuses = %v, want two 302s then 429", codes)
+	}
+}
+
+func TestYAMLDeprecated(t *testing.T) {
+	h, err := YAMLHandler([]byte("- path: /old\n  url: https://example.com/\n  deprecated: true\n  deprecation_message: use /new\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/old", nil))
+	if rec.Code != http.StatusFound || rec.Header().Get("Warning") != `299 - "use /new"` {
+		t.Errorf("GET /old = %d with Warning %q, want 302 with the deprecation message", rec.Code, rec.Header().Get("Warning"))
+	}
+}
+
+func TestYAMLHandlerErrors(t *testing.T) {
+	for _, yaml := range []string{
+		"- path: /a\n  url: https://example.com/\n  status: 200\n",
+		"- path: /a\n  url: https://example.com/\n  ignore_case: true\n- path: /A\n  url: https://example.com/\n  ignore_case: true\n",
+		"not: [valid",
+	} {
+		if _, err := YAMLHandler([]byte(yaml), nil); err == nil {
+			t.Errorf("YAMLHandler(%q) succeeded, want an error", yaml)
+		}
+	}
+}
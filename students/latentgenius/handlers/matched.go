@@ -0,0 +1,33 @@
+package handlers
+
+import "net/http"
+
+// MatchedRuleHeader is the response header naming the rule that
+// produced a redirect, see WithMatchedRule.
+const MatchedRuleHeader = "X-Matched-Rule"
+
+// WithMatchedRule makes the handler set an X-Matched-Rule header on its
+// redirects naming the rule that matched: the mapped path for the map,
+// YAML, JSON and DB handlers (the canonical one when matched
+// case-insensitively), the prefix for PrefixHandler. It is meant for
+// tuning overlapping rule sets and is off by default, since it exposes
+// how the rules are laid out.
+func WithMatchedRule() Option {
+	return func(o *options) {
+		o.matchedRule = true
+	}
+}
+
+// setMatchedRule names rule in the X-Matched-Rule header, or only in
+// the trailer of that name under WithDestinationTrailer alone. A header
+// declared as a trailer is sent again at the end by net/http, so the
+// trailer key is set only when the header isn't, or the rule would be
+// sent twice.
+func (o *options) setMatchedRule(w http.ResponseWriter, rule string) {
+	switch {
+	case o.matchedRule:
+		w.Header().Set(MatchedRuleHeader, rule)
+	case o.trailer:
+		w.Header()[http.TrailerPrefix+MatchedRuleHeader] = []string{rule}
+	}
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import "fmt"
+
+// WithMaxEntries makes the handlers that load a mapping (YAMLHandler,
+// JSONHandler, JSONCHandler, ProtoHandler, EmbedHandler, RemoteHandler
+// and SheetHandler) refuse one with more than max entries, as a safety
+// valve against loading a pathological input by mistake. The input is
+// still parsed before it is counted; the limit bounds what is kept and
+// served. Loaders return an error naming the limit, and the pollers
+// keep serving their last good mapping. By default there is no limit.
+func WithMaxEntries(max int) Option {
+	return func(o *options) {
+		o.maxEntries = max
+	}
+}
+
+// checkEntries returns an error if a mapping of n entries exceeds the
+// configured maximum.
+func (o *options) checkEntries(n int) error {
+	if o.maxEntries > 0 && n > o.maxEntries {
+		return fmt.Errorf("mapping has %d entries, more than the limit of %d", n, o.maxEntries)
+	}
+	return nil
+}
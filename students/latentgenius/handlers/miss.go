@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+)
+
+// MissFunc derives a destination for a request whose path isn't mapped,
+// turning a static mapping into a programmable resolver, e.g. sending
+// /gh/owner/repo to https://github.com/owner/repo. It returns ok false
+// to let the fallback handle the request, and cache true to remember
+// the destination for later requests to the same path. MissFuncs are
+// called concurrently and must be safe for concurrent use.
+type MissFunc func(r *http.Request) (url string, cache bool, ok bool)
+
+// WithMissHandler makes the handler call miss on a lookup miss, before
+// the fallback runs. Cached destinations live in memory, per handler;
+// since the paths come from clients, only the 10000 most recently used
+// are kept.
+func WithMissHandler(miss MissFunc) Option {
+	return func(o *options) {
+		o.miss = miss
+	}
+}
+
+// missFallback returns the handler a source handler calls on a lookup
+// miss: fallback, or http.NotFoundHandler() if it is nil, preceded by
+// the configured MissFunc.
+func (o *options) missFallback(source string, fallback http.Handler) http.Handler {
+	fallback = countFallback(orNotFound(fallback))
+	if o.miss == nil {
+		return fallback
+	}
+	cache := newMissCache(missCacheSize)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if dst, ok := cache.Load(r.URL.Path); ok {
+			o.redirect(w, r, source, dst, http.StatusFound, fallback)
+			return
+		}
+		dst, store, ok := o.miss(r)
+		if !ok {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		if store {
+			cache.Store(r.URL.Path, dst)
+		}
+		o.redirect(w, r, source, dst, http.StatusFound, fallback)
+	})
+}
+
+// missCacheSize is the number of destinations a miss cache holds.
+const missCacheSize = 10000
+
+// missCache is a least recently used cache of MissFunc destinations,
+// keyed by path.
+type missCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // of *missEntry, most recently used first
+	paths map[string]*list.Element
+}
+
+type missEntry struct {
+	path, dst string
+}
+
+func newMissCache(size int) *missCache {
+	return &missCache{size: size, order: list.New(), paths: make(map[string]*list.Element)}
+}
+
+// Load returns the destination cached for path, if any.
+func (c *missCache) Load(path string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.paths[path]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*missEntry).dst, true
+}
+
+// Store caches dst for path, evicting the least recently used entry if
+// the cache is full.
+func (c *missCache) Store(path, dst string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.paths[path]; ok {
+		e.Value.(*missEntry).dst = dst
+		c.order.MoveToFront(e)
+		return
+	}
+	c.paths[path] = c.order.PushFront(&missEntry{path, dst})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.paths, oldest.Value.(*missEntry).path)
+	}
+}
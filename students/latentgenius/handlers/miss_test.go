@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gophercises/urlshort/students/latentgenius/handlers/handlerstest"
+)
+
+func TestMissHandler(t *testing.T) {
+	var calls int64
+	miss := func(r *http.Request) (string, bool, bool) {
+		atomic.AddInt64(&calls, 1)
+		if repo, ok := strings.CutPrefix(r.URL.Path, "/gh/"); ok {
+			return "https://github.com/" + repo, true, true
+		}
+		return "", false, false
+	}
+	h := MapHandler(map[string]string{"/a": "https://example.com/a"}, nil, WithMissHandler(miss))
+
+	tests := []struct {
+		path     string
+		status   int
+		location string
+	}{
+		{"/a", http.StatusFound, "https://example.com/a"},
+		{"/gh/golang/go", http.StatusFound, "https://github.com/golang/go"},
+		{"/gh/golang/go", http.StatusFound, "https://github.com/golang/go"},
+		{"/other", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		status, location, _ := handlerstest.ProbeHandler(h, "GET", tt.path)
+		if status != tt.status || location != tt.location {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.path, status, location, tt.status, tt.location)
+		}
+	}
+	// The repeated /gh/golang/go was served from the cache.
+	if calls != 2 {
+		t.Errorf("miss called %d times, want 2", calls)
+	}
+}
+
+func TestMissCacheEvicts(t *testing.T) {
+	c := newMissCache(2)
+	c.Store("/a", "1")
+	c.Store("/b", "2")
+	c.Load("/a")
+	c.Store("/c", "3")
+	if _, ok := c.Load("/b"); ok {
+		t.Error("/b is still cached, want it evicted as least recently used")
+	}
+	for _, path := range []string{"/a", "/c"} {
+		if _, ok := c.Load(path); !ok {
+			t.Errorf("%s was evicted", path)
+		}
+	}
+	if len(c.paths) != 2 || c.order.Len() != 2 {
+		t.Errorf("cache holds %d paths and %d entries, want 2", len(c.paths), c.order.Len())
+	}
+}
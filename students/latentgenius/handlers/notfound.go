@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// JSON404Handler will return an http.HandlerFunc answering every
+// request with 404 Not Found and a JSON body naming the path, such as
+//
+//	{"error":"not_found","path":"/unknown"}
+//
+// It is meant as the fallback of API-oriented deployments, whose
+// clients can't parse the plain-text body of http.NotFoundHandler.
+func JSON404Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(struct {
+			Error string `json:"error"`
+			Path  string `json:"path"`
+		}{"not_found", r.URL.Path})
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Identifiers of the built-in source handlers, as reported in the
+// X-Redirect-By header.
+const (
+	sourceMap  = "map"
+	sourceYAML = "yaml"
+	sourceJSON = "json"
+	sourceDB   = "db"
+)
+
+// Option configures optional behaviour of the source handlers
+// (MapHandler, YAMLHandler, JSONHandler and DBHandler). The zero set of
+// options keeps the plain redirect behaviour.
+type Option func(*options)
+
+type options struct {
+	redirectBy      bool
+	rootURL         string
+	tracer          trace.Tracer
+	maxHops         int
+	miss            MissFunc
+	blankStatus     int
+	webhook         *Webhook
+	shortlinkBase   string
+	recorder        *Recorder
+	attribution     Attribution
+	variantName     string
+	matchedRule     bool
+	parentFallback  bool
+	maxEntries      int
+	canonicalize    func(string) string
+	jsonDestination func(*http.Request) bool
+	hostBudget      *pathLimiter
+	strictYAML      bool
+	versionParam    string
+	schemes         map[string]bool
+	schemeStatus    int
+	choicePage      *template.Template
+	jitter          time.Duration
+	trailer         bool
+	pathHeader      string
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithRedirectBy makes the handler stamp an X-Redirect-By header naming
+// the source that produced the redirect ("map", "yaml", "json" or "db").
+// This is useful to tell which layer matched when chaining sources. It
+// is off by default to avoid leaking implementation details.
+func WithRedirectBy() Option {
+	return func(o *options) {
+		o.redirectBy = true
+	}
+}
+
+// RootRedirect makes the handler redirect the bare root path "/" to
+// url, e.g. to point the bare domain at a marketing site. It only
+// applies to the exact root path and takes precedence over any mapping
+// for "/"; every other path is resolved as usual.
+func RootRedirect(url string) Option {
+	return func(o *options) {
+		o.rootURL = url
+	}
+}
+
+// serveRoot redirects the request to the configured root URL and
+// reports whether it did so.
+func (o *options) serveRoot(w http.ResponseWriter, r *http.Request, source string, fallback http.Handler) bool {
+	if o.rootURL == "" || r.URL.Path != "/" {
+		return false
+	}
+	o.redirect(w, r, source, o.rootURL, http.StatusFound, fallback)
+	return true
+}
+
+// handler wraps the request handling of a source handler with the
+// behaviour shared by all of them: the path header, maintenance mode,
+// decision recording and tracing.
+func (o *options) handler(source string, h http.HandlerFunc) http.HandlerFunc {
+	inner := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&counters.requests, 1)
+		if serveMaintenance(w, r) {
+			return
+		}
+		h(w, r)
+	}
+	if o.recorder != nil {
+		inner = o.recorder.record(source, inner)
+	}
+	outer := o.instrument(source, inner)
+	if o.pathHeader == "" {
+		return outer
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		outer(w, o.fromPathHeader(r))
+	}
+}
+
+// redirect replies to the request with a redirect to url, applying the
+// configured options. source identifies the handler that matched. A
+// GoneURL destination is answered with 410 Gone instead, a LegalPrefix
+// one with 451 Unavailable For Legal Reasons, and one with a disallowed
+// scheme falls through to the fallback. The request body is left
+// untouched, for clients to replay on a 307 or 308.
+func (o *options) redirect(w http.ResponseWriter, r *http.Request, source, url string, code int, fallback http.Handler) {
+	traceRedirect(r, url)
+	noteDecision(w, url)
+	if url == GoneURL {
+		gone(w)
+		return
+	}
+	if blocked(url) {
+		unavailableForLegalReasons(w, url)
+		return
+	}
+	if !o.allowedScheme(w, r, source, url, fallback) {
+		return
+	}
+	if !o.checkHops(w, r) {
+		return
+	}
+	if !o.withinBudget(w, url) {
+		return
+	}
+	if !o.waitJitter(r) {
+		return
+	}
+	if o.redirectBy {
+		w.Header().Set("X-Redirect-By", source)
+	}
+	o.setShortlink(w, r)
+	o.setDestinationTrailer(w, url)
+	if o.jsonDestination != nil && o.jsonDestination(r) {
+		code = http.StatusOK
+		writeJSONDestination(w, url)
+	} else {
+		http.Redirect(w, r, url, code)
+	}
+	atomic.AddInt64(&counters.redirects, 1)
+	if o.webhook != nil {
+		o.webhook.Send(RedirectEvent{
+			Time:        time.Now(),
+			Source:      source,
+			Host:        r.Host,
+			Path:        r.URL.Path,
+			Destination: url,
+			Status:      code,
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	pathsToUrls := map[string]string{
+		"/a":      "https://example.com/a",
+		"/docs":   "https://docs.example.com/",
+		"/blank":  " ",
+		"/script": "javascript:alert(1)",
+		"/ftp":    "ftp://example.com/file",
+	}
+	tests := []struct {
+		name     string
+		opts     []Option
+		target   string
+		header   http.Header // request headers
+		status   int
+		location string
+		want     http.Header // response headers, checked by first value
+	}{
+		{"plain", nil, "/a", nil, http.StatusFound, "https://example.com/a",
+			http.Header{"X-Redirect-By": {""}, MatchedRuleHeader: {""}, HopsHeader: {""}}},
+		{"redirect by", []Option{WithRedirectBy()}, "/a", nil, http.StatusFound, "https://example.com/a",
+			http.Header{"X-Redirect-By": {"map"}}},
+		{"root redirect", []Option{RootRedirect("https://www.example.com/")}, "/", nil, http.StatusFound, "https://www.example.com/", nil},
+		{"root redirect other path", []Option{RootRedirect("https://www.example.com/")}, "/a", nil, http.StatusFound, "https://example.com/a", nil},
+		{"matched rule", []Option{WithMatchedRule(), WithParentFallback()}, "/docs/install", nil, http.StatusFound, "https://docs.example.com/",
+			http.Header{MatchedRuleHeader: {"/docs"}}},
+		{"parent fallback off", nil, "/docs/install", nil, http.StatusNotFound, "", nil},
+		{"canonicalize", []Option{WithCanonicalize(strings.ToUpper)}, "/A", nil, http.StatusFound, "https://example.com/a", nil},
+		{"hops", []Option{WithHopLimit(3)}, "/a", http.Header{HopsHeader: {"1"}}, http.StatusFound, "https://example.com/a",
+			http.Header{HopsHeader: {"2"}}},
+		{"hops exhausted", []Option{WithHopLimit(3)}, "/a", http.Header{HopsHeader: {"3"}}, http.StatusLoopDetected, "", nil},
+		{"json destination", []Option{WithJSONDestination(UserAgentContains("App/"))}, "/a", http.Header{"User-Agent": {"App/1.0"}}, http.StatusOK, "",
+			http.Header{"Content-Type": {"application/json"}}},
+		{"json destination browser", []Option{WithJSONDestination(UserAgentContains("App/"))}, "/a", http.Header{"User-Agent": {"Mozilla/5.0"}}, http.StatusFound, "https://example.com/a", nil},
+		{"blank", nil, "/blank", nil, http.StatusNotFound, "", nil},
+		{"blank status", []Option{WithBlankStatus(http.StatusServiceUnavailable)}, "/blank", nil, http.StatusServiceUnavailable, "", nil},
+		{"scheme refused", nil, "/script", nil, http.StatusNotFound, "", nil},
+		{"scheme status", []Option{WithDisallowedSchemeStatus(http.StatusForbidden)}, "/script", nil, http.StatusForbidden, "", nil},
+		{"scheme allowed", []Option{WithAllowedSchemes("https", "FTP")}, "/ftp", nil, http.StatusFound, "ftp://example.com/file", nil},
+		{"scheme narrowed", []Option{WithAllowedSchemes("ftp")}, "/a", nil, http.StatusNotFound, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := MapHandler(pathsToUrls, nil, tt.opts...)
+			r := httptest.NewRequest("GET", tt.target, nil)
+			for k, v := range tt.header {
+				r.Header[k] = v
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			if rec.Code != tt.status || rec.Header().Get("Location") != tt.location {
+				t.Errorf("GET %s = %d %q, want %d %q", tt.target, rec.Code, rec.Header().Get("Location"), tt.status, tt.location)
+			}
+			for k, v := range tt.want {
+				if got := rec.Header().Get(k); got != v[0] {
+					t.Errorf("header %s = %q, want %q", k, got, v[0])
+				}
+			}
+		})
+	}
+}
+
+func TestVersionParam(t *testing.T) {
+	probe := func(pathsToUrls map[string]string) string {
+		rec := httptest.NewRecorder()
+		MapHandler(pathsToUrls, nil, WithVersionParam("v")).ServeHTTP(rec, httptest.NewRequest("GET", "/a", nil))
+		return rec.Header().Get("Location")
+	}
+	before := probe(map[string]string{"/a": "https://example.com/a?x=1"})
+	after := probe(map[string]string{"/a": "https://example.com/a?x=1", "/b": "https://example.com/b"})
+	if !strings.HasPrefix(before, "https://example.com/a?v=") || !strings.Contains(before, "x=1") {
+		t.Errorf("Location = %q, want the destination with a v parameter", before)
+	}
+	if before == after {
+		t.Errorf("version %q didn't change with the mapping", before)
+	}
+	if again := probe(map[string]string{"/a": "https://example.com/a?x=1"}); again != before {
+		t.Errorf("version changed from %q to %q for the same mapping", before, again)
+	}
+}
+
+func TestHostBudget(t *testing.T) {
+	budget := WithHostBudget(map[string]float64{"Example.com": 1})
+	a := MapHandler(map[string]string{"/a": "https://example.com:8443/a"}, nil, budget)
+	b := MapHandler(map[string]string{"/b": "https://example.com/b", "/other": "https://other.example/"}, nil, budget)
+
+	probe := func(h http.Handler, path string) int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec.Code
+	}
+	if code := probe(a, "/a"); code != http.StatusFound {
+		t.Errorf("first redirect = %d, want %d", code, http.StatusFound)
+	}
+	// The budget is shared between the handlers built with it.
+	if code := probe(b, "/b"); code != http.StatusServiceUnavailable {
+		t.Errorf("redirect over budget = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	if code := probe(b, "/other"); code != http.StatusFound {
+		t.Errorf("redirect to an unbudgeted host = %d, want %d", code, http.StatusFound)
+	}
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import "strings"
+
+// WithParentFallback makes the map-based handlers (MapHandler,
+// YAMLHandler, JSONHandler and the like) resolve an unmapped path to
+// its nearest mapped ancestor, for deep links into a moved section: a
+// miss on /docs/v2/install tries /docs/v2/, /docs/v2, /docs/ and /docs,
+// in that order, and redirects to the destination of the first one
+// mapped, as is, with its status. The root path is never tried.
+//
+// Exact matches always win over an ancestor, and an ancestor over the
+// miss handler and the fallback. Unlike PrefixHandler, the rest of the
+// path is not appended to the destination; a PrefixHandler in front of
+// or behind this handler takes precedence according to its position in
+// the chain.
+func WithParentFallback() Option {
+	return func(o *options) {
+		o.parentFallback = true
+	}
+}
+
+// ancestor looks up the nearest mapped ancestor of path.
+func (m *mapping) ancestor(path string) (key, dst string, code int, ok bool) {
+	path = strings.TrimSuffix(path, "/")
+	for {
+		i := strings.LastIndexByte(path, '/')
+		if i <= 0 {
+			return "", "", 0, false
+		}
+		path = path[:i]
+		if key, dst, code, ok = m.lookup(path + "/"); ok {
+			return key, dst, code, ok
+		}
+		if key, dst, code, ok = m.lookup(path); ok {
+			return key, dst, code, ok
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// maxPathHeaderLength bounds the value of a path header; longer values
+// are ignored.
+const maxPathHeaderLength = 2048
+
+// WithPathHeader makes the handler look up the path, and query, given
+// in the request header name, such as X-Original-URL or
+// X-Forwarded-Uri, instead of the request's own, when the header is
+// present. This lets the shortener run as the auth-request or
+// subrequest handler of a reverse proxy like nginx or Traefik, which
+// sends the original request target in a header. The whole request,
+// fallback included, then sees the header's path.
+//
+// The header is supplied by the client unless the proxy overwrites it,
+// so the value is checked: it must be an origin-form target starting
+// with a single / and be at most 2048 bytes long, and its dot segments
+// are resolved, so /a/../b is looked up as /b. A value that fails the
+// checks is logged and ignored.
+func WithPathHeader(name string) Option {
+	return func(o *options) {
+		o.pathHeader = name
+	}
+}
+
+// fromPathHeader returns r with the target of the path header if the
+// request carries a valid one, and r otherwise.
+func (o *options) fromPathHeader(r *http.Request) *http.Request {
+	value := r.Header.Get(o.pathHeader)
+	if value == "" {
+		return r
+	}
+	u, ok := parsePathHeader(value)
+	if !ok {
+		log.Printf("ignoring invalid %s header %q", o.pathHeader, value)
+		return r
+	}
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = u
+	r2.RequestURI = u.RequestURI()
+	return r2
+}
+
+// parsePathHeader parses value as an origin-form request target and
+// resolves its dot segments.
+func parsePathHeader(value string) (*url.URL, bool) {
+	if len(value) > maxPathHeaderLength || !strings.HasPrefix(value, "/") || strings.HasPrefix(value, "//") {
+		return nil, false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < 0x20 || value[i] == 0x7f {
+			return nil, false
+		}
+	}
+	u, err := url.ParseRequestURI(value)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return nil, false
+	}
+	cleaned := path.Clean(u.Path)
+	if strings.HasSuffix(u.Path, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	if cleaned != u.Path {
+		u.Path = cleaned
+		u.RawPath = ""
+	}
+	return u, true
+}
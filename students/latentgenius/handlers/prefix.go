@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const sourcePrefix = "prefix"
+
+// PrefixHandler will return an http.HandlerFunc that redirects whole
+// path trees to a new location. Each key in prefixesToUrls is a path
+// prefix and its value the destination the tree moves to; whatever
+// remains of the request path after the prefix is appended to the
+// destination's path. For instance, with
+//
+//	"/blog/": "https://new.example.com/archive/"
+//
+// a request for /blog/2020/01/post is redirected to
+// https://new.example.com/archive/2020/01/post.
+//
+// Prefixes only match on whole path segments, so /blog matches /blog
+// and /blog/post but not /blogger. When several prefixes match, the
+// longest one wins; prefixes are indexed by path segment, so lookups
+// don't slow down as rules are added. The remainder is carried over
+// in its escaped form, so percent-encoded characters reach the
+// destination unchanged. If no prefix matches, the fallback
+// http.Handler will be called instead.
+func PrefixHandler(prefixesToUrls map[string]string, fallback http.Handler, opts ...Option) http.HandlerFunc {
+	o := newOptions(opts)
+	fallback = o.missFallback(sourcePrefix, fallback)
+	prefixes := make([]string, 0, len(prefixesToUrls))
+	for p := range prefixesToUrls {
+		prefixes = append(prefixes, p)
+	}
+	trie := newPrefixTrie(prefixes)
+
+	return o.handler(sourcePrefix, func(w http.ResponseWriter, r *http.Request) {
+		if o.serveRoot(w, r, sourcePrefix, fallback) {
+			return
+		}
+		path := r.URL.EscapedPath()
+		if p, ok := trie.longest(path); ok {
+			if o.blank(w, r, sourcePrefix, prefixesToUrls[p], fallback) {
+				return
+			}
+			dst, err := appendPath(prefixesToUrls[p], path[len(p):])
+			if err == nil {
+				o.setMatchedRule(w, p)
+				o.redirect(w, r, sourcePrefix, dst, http.StatusFound, fallback)
+				return
+			}
+		}
+		fallback.ServeHTTP(w, r)
+	})
+}
+
+// appendPath appends the escaped path remainder to the path of the
+// destination URL dst, keeping dst's query and fragment intact.
+func appendPath(dst, remainder string) (string, error) {
+	if remainder == "" || dst == GoneURL || blocked(dst) {
+		return dst, nil
+	}
+	u, err := url.Parse(dst)
+	if err != nil {
+		return "", err
+	}
+	escaped := u.EscapedPath()
+	if strings.HasSuffix(escaped, "/") && strings.HasPrefix(remainder, "/") {
+		remainder = remainder[1:]
+	} else if !strings.HasSuffix(escaped, "/") && !strings.HasPrefix(remainder, "/") {
+		remainder = "/" + remainder
+	}
+	escaped += remainder
+	unescaped, err := url.PathUnescape(escaped)
+	if err != nil {
+		return "", err
+	}
+	u.Path = unescaped
+	u.RawPath = escaped
+	return u.String(), nil
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gophercises/urlshort/students/latentgenius/handlers/handlerstest"
+)
+
+func TestPrefixHandler(t *testing.T) {
+	h := PrefixHandler(map[string]string{
+		"/blog/":      "https://new.example.com/archive/",
+		"/blog/2020/": "https://old.example.com/2020/",
+		"/docs":       "https://docs.example.com/v2?lang=en",
+	}, nil)
+
+	tests := []struct {
+		path     string
+		status   int
+		location string
+	}{
+		{"/blog/post", http.StatusFound, "https://new.example.com/archive/post"},
+		{"/blog/2019/01/post", http.StatusFound, "https://new.example.com/archive/2019/01/post"},
+		{"/blog/2020/01/post", http.StatusFound, "https://old.example.com/2020/01/post"},
+		{"/blog/a%2Fb", http.StatusFound, "https://new.example.com/archive/a%2Fb"},
+		{"/blog/hello%20world", http.StatusFound, "https://new.example.com/archive/hello%20world"},
+		{"/docs", http.StatusFound, "https://docs.example.com/v2?lang=en"},
+		{"/docs/guide/intro", http.StatusFound, "https://docs.example.com/v2/guide/intro?lang=en"},
+		{"/blogger", http.StatusNotFound, ""},
+		{"/documents", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		status, location, _ := handlerstest.ProbeHandler(h, "GET", tt.path)
+		if status != tt.status || location != tt.location {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.path, status, location, tt.status, tt.location)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Preview is the expansion of a short link returned by PreviewHandler.
+type Preview struct {
+	Path string `json:"path"`
+	// Hops are the redirects followed, in order.
+	Hops []PreviewHop `json:"hops"`
+	// Final is where the chain ends: the first destination that isn't
+	// another short link. It is empty if the chain loops or is cut off.
+	Final string `json:"final,omitempty"`
+	// Loop is set if the chain comes back to a path it went through.
+	Loop bool `json:"loop,omitempty"`
+	// Truncated is set if the chain was cut off at the hop limit.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// PreviewHop is one redirect of a Preview.
+type PreviewHop struct {
+	Path        string `json:"path"`
+	Status      int    `json:"status"`
+	Destination string `json:"destination"`
+}
+
+// PreviewHandler will return an http.HandlerFunc serving a preview
+// endpoint that expands a short link without redirecting, so users can
+// vet where it leads: for a GET with the short path in the path query
+// parameter, as in /preview?path=/promo, it resolves the path through
+// resolver, follows the destination for as long as it is another short
+// link, and responds with the chain as a JSON Preview:
+//
+//	{"path":"/promo","hops":[
+//		{"path":"/promo","status":302,"destination":"/spring"},
+//		{"path":"/spring","status":301,"destination":"https://shop.example/spring"}],
+//	 "final":"https://shop.example/spring"}
+//
+// Only destinations on this shortener are followed: relative ones and
+// those on the request's host or one of hosts. The first other
+// destination is the final one; nothing outside is ever fetched. At
+// most maxHops redirects are followed, and a chain coming back to a
+// path it went through is reported as a loop. A path that resolver
+// doesn't redirect gets 404 Not Found.
+//
+// resolver is sent the requests as if from a client, so they count in
+// Counters and webhook events and take from rate limits like any other.
+func PreviewHandler(resolver http.Handler, maxHops int, hosts ...string) http.HandlerFunc {
+	internal := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		internal[strings.ToLower(host)] = true
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		path := r.URL.Query().Get("path")
+		if !strings.HasPrefix(path, "/") {
+			http.Error(w, "path must start with /", http.StatusBadRequest)
+			return
+		}
+		preview := Preview{Path: path, Hops: []PreviewHop{}}
+		seen := make(map[string]bool)
+		target := path
+		for {
+			if seen[target] {
+				preview.Loop = true
+				break
+			}
+			if len(preview.Hops) == maxHops {
+				preview.Truncated = true
+				break
+			}
+			seen[target] = true
+			status, dst, ok := resolveOnce(resolver, r, target)
+			if !ok {
+				if len(preview.Hops) == 0 {
+					http.Error(w, "404 not a short link", http.StatusNotFound)
+					return
+				}
+				preview.Final = preview.Hops[len(preview.Hops)-1].Destination
+				break
+			}
+			preview.Hops = append(preview.Hops, PreviewHop{Path: target, Status: status, Destination: dst})
+			next, ok := internalTarget(dst, r.Host, internal)
+			if !ok {
+				preview.Final = dst
+				break
+			}
+			target = next
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(preview)
+	}
+}
+
+// resolveOnce sends h a GET for target, on the host of r, and returns
+// the status and destination if h redirects it.
+func resolveOnce(h http.Handler, r *http.Request, target string) (status int, dst string, ok bool) {
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, target, nil)
+	if err != nil {
+		return 0, "", false
+	}
+	req.Host = r.Host
+	req.RequestURI = target
+	cw := &checkWriter{header: make(http.Header)}
+	h.ServeHTTP(cw, req)
+	dst = cw.header.Get("Location")
+	if cw.status < 300 || cw.status > 399 || dst == "" {
+		return 0, "", false
+	}
+	return cw.status, dst, true
+}
+
+// internalTarget returns the request target of dst if it is on this
+// shortener: relative, or on host or one of internal.
+func internalTarget(dst, host string, internal map[string]bool) (string, bool) {
+	u, err := url.Parse(dst)
+	if err != nil {
+		return "", false
+	}
+	if u.Host != "" && !strings.EqualFold(u.Host, host) && !internal[strings.ToLower(u.Host)] {
+		return "", false
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return "", false
+	}
+	target := u.EscapedPath()
+	if !strings.HasPrefix(target, "/") {
+		return "", false
+	}
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+	return target, true
+}
@@ -0,0 +1,47 @@
+package handlers
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative redirects.proto
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const sourceProto = "proto"
+
+// ProtoHandler will build an http.HandlerFunc from a Redirects message,
+// as distributed by a protobuf config pipeline. It behaves like
+// YAMLHandler, with each Redirect being one entry, except that a
+// Redirect may carry its own redirect status. A zero status means the
+// default of 302 Found.
+//
+// The only errors that can be returned are related to invalid statuses
+// and aliases that can't be resolved.
+func ProtoHandler(redirects *Redirects, fallback http.Handler, opts ...Option) (http.HandlerFunc, error) {
+	o := newOptions(opts)
+	if err := o.checkEntries(len(redirects.GetRedirects())); err != nil {
+		return nil, err
+	}
+	m, err := buildMapping(parseProto(redirects))
+	if err != nil {
+		return nil, err
+	}
+	return mappingHandler(sourceProto, m, fallback, o), nil
+}
+
+// parseProto converts a Redirects message into the entry shape produced
+// by parseYAML, so it can be fed to buildMapping.
+func parseProto(redirects *Redirects) []map[string]string {
+	entries := make([]map[string]string, 0, len(redirects.GetRedirects()))
+	for _, r := range redirects.GetRedirects() {
+		entry := map[string]string{
+			"path": r.GetPath(),
+			"url":  r.GetUrl(),
+		}
+		if r.GetStatus() != 0 {
+			entry["status"] = strconv.Itoa(int(r.GetStatus()))
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
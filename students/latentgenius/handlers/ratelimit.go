@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// limiterSweep is how often idle token buckets are looked for. A bucket
+// is only dropped once it has been unused long enough to be full again,
+// so dropping it, and starting over with a full one, loses nothing.
+const limiterSweep = time.Minute
+
+// pathLimiter enforces per-path rate limits with a token bucket per
+// path, created on a path's first request. WithHostBudget uses one keyed
+// by destination host instead.
+type pathLimiter struct {
+	rates map[string]float64 // path -> requests per second
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// buildLimits collects the rate_limit settings of the entries. It
+// returns nil if no entry has one.
+func buildLimits(entries []map[string]string) (*pathLimiter, error) {
+	rates := make(map[string]float64)
+	for _, entry := range entries {
+		s, ok := entry["rate_limit"]
+		if !ok {
+			continue
+		}
+		rate, err := strconv.ParseFloat(s, 64)
+		if err != nil || rate <= 0 || math.IsInf(rate, 0) {
+			return nil, fmt.Errorf("path %s: invalid rate_limit %q", entry["path"], s)
+		}
+		rates[entry["path"]] = rate
+	}
+	if len(rates) == 0 {
+		return nil, nil
+	}
+	return &pathLimiter{
+		rates:   rates,
+		buckets: make(map[string]*tokenBucket),
+	}, nil
+}
+
+// allow reports whether a request for path may go ahead, taking a
+// token from its bucket if so. Paths without a limit, and all paths of
+// a nil pathLimiter, are always allowed.
+func (l *pathLimiter) allow(path string) bool {
+	if l == nil {
+		return true
+	}
+	rate, ok := l.rates[path]
+	if !ok {
+		return true
+	}
+	burst := math.Max(rate, 1)
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastSweep) > limiterSweep {
+		for p, b := range l.buckets {
+			r := l.rates[p]
+			if b.tokens+now.Sub(b.last).Seconds()*r >= math.Max(r, 1) {
+				delete(l.buckets, p)
+			}
+		}
+		l.lastSweep = now
+	}
+	b, ok := l.buckets[path]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		l.buckets[path] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func tooManyRequests(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	http.Error(w, "429 too many requests", http.StatusTooManyRequests)
+}
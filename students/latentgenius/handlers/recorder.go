@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// recordedHeaders are the request headers a Recorder keeps.
+var recordedHeaders = []string{"User-Agent", "Referer", "Accept", "X-Forwarded-For", "X-Forwarded-Proto"}
+
+// Decision is a redirect decision captured by a Recorder: what was
+// asked, and how the handler answered.
+type Decision struct {
+	Time        time.Time         `json:"time"`
+	Method      string            `json:"method"`
+	Host        string            `json:"host"`
+	Path        string            `json:"path"`
+	Query       string            `json:"query,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Source      string            `json:"source"`
+	Matched     bool              `json:"matched"`
+	Destination string            `json:"destination,omitempty"`
+	Status      int               `json:"status"`
+}
+
+// Recorder captures the redirect decisions of a sample of requests in a
+// fixed-size ring buffer, to reproduce what happened when a link
+// "didn't redirect". It is a diagnostic aid, not analytics: the buffer
+// only holds the latest decisions and lives in memory. A Recorder is
+// also an http.Handler serving the buffer as JSON, oldest first, to be
+// mounted on a debug endpoint.
+type Recorder struct {
+	every uint64 // record one in every requests
+	seen  uint64
+
+	mu      sync.Mutex
+	ring    []Decision
+	next    int
+	wrapped bool
+}
+
+// NewRecorder returns a Recorder keeping the last size decisions out of
+// one in every requests. A non-positive every records every request.
+func NewRecorder(size, every int) *Recorder {
+	if size < 1 {
+		size = 1
+	}
+	if every < 1 {
+		every = 1
+	}
+	return &Recorder{
+		every: uint64(every),
+		ring:  make([]Decision, size),
+	}
+}
+
+// WithRecorder makes the handler capture its decisions in rec.
+func WithRecorder(rec *Recorder) Option {
+	return func(o *options) {
+		o.recorder = rec
+	}
+}
+
+// Decisions returns the recorded decisions, oldest first.
+func (rec *Recorder) Decisions() []Decision {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if !rec.wrapped {
+		return append([]Decision(nil), rec.ring[:rec.next]...)
+	}
+	return append(append([]Decision(nil), rec.ring[rec.next:]...), rec.ring[:rec.next]...)
+}
+
+// ServeHTTP serves the recorded decisions as a JSON array.
+func (rec *Recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec.Decisions())
+}
+
+// sample reports whether the current request should be recorded.
+func (rec *Recorder) sample() bool {
+	return (atomic.AddUint64(&rec.seen, 1)-1)%rec.every == 0
+}
+
+func (rec *Recorder) add(d Decision) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.ring[rec.next] = d
+	rec.next++
+	if rec.next == len(rec.ring) {
+		rec.next = 0
+		rec.wrapped = true
+	}
+}
+
+// record wraps h so that a sample of its requests is recorded.
+func (rec *Recorder) record(source string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !rec.sample() {
+			h(w, r)
+			return
+		}
+		dw := &decisionWriter{StatusWriter: NewStatusWriter(w)}
+		h(dw, r)
+		d := Decision{
+			Time:        time.Now(),
+			Method:      r.Method,
+			Host:        r.Host,
+			Path:        r.URL.Path,
+			Query:       r.URL.RawQuery,
+			Source:      source,
+			Matched:     dw.matched,
+			Destination: dw.destination,
+			Status:      dw.Status(),
+		}
+		for _, name := range recordedHeaders {
+			if v := r.Header.Get(name); v != "" {
+				if d.Headers == nil {
+					d.Headers = make(map[string]string)
+				}
+				d.Headers[name] = v
+			}
+		}
+		rec.add(d)
+	}
+}
+
+// decisionWriter notes the redirect decision made through it.
+type decisionWriter struct {
+	*StatusWriter
+	matched     bool
+	destination string
+}
+
+// noteDecision marks the request behind w as matched, if w records
+// decisions.
+func noteDecision(w http.ResponseWriter, dst string) {
+	if dw, ok := w.(*decisionWriter); ok {
+		dw.matched = true
+		dw.destination = dst
+	}
+}
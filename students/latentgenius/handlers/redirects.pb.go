@@ -0,0 +1,193 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: redirects.proto
+
+package handlers
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Redirect maps a path to the URL it redirects to.
+type Redirect struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Url   string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// HTTP status of the redirect, e.g. 301 or 308. Zero means the
+	// handler's default, 302 Found.
+	Status        int32 `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Redirect) Reset() {
+	*x = Redirect{}
+	mi := &file_redirects_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Redirect) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Redirect) ProtoMessage() {}
+
+func (x *Redirect) ProtoReflect() protoreflect.Message {
+	mi := &file_redirects_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Redirect.ProtoReflect.Descriptor instead.
+func (*Redirect) Descriptor() ([]byte, []int) {
+	return file_redirects_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Redirect) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Redirect) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Redirect) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+// Redirects is a set of redirect rules, as distributed by a config
+// service.
+type Redirects struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Redirects     []*Redirect            `protobuf:"bytes,1,rep,name=redirects,proto3" json:"redirects,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Redirects) Reset() {
+	*x = Redirects{}
+	mi := &file_redirects_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Redirects) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Redirects) ProtoMessage() {}
+
+func (x *Redirects) ProtoReflect() protoreflect.Message {
+	mi := &file_redirects_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Redirects.ProtoReflect.Descriptor instead.
+func (*Redirects) Descriptor() ([]byte, []int) {
+	return file_redirects_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Redirects) GetRedirects() []*Redirect {
+	if x != nil {
+		return x.Redirects
+	}
+	return nil
+}
+
+var File_redirects_proto protoreflect.FileDescriptor
+
+const file_redirects_proto_rawDesc = "" +
+	"\n" +
+	"\x0fredirects.proto\x12\burlshort\"H\n" +
+	"\bRedirect\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\x05R\x06status\"=\n" +
+	"\tRedirects\x120\n" +
+	"\tredirects\x18\x01 \x03(\v2\x12.urlshort.RedirectR\tredirectsB@Z>github.com/gophercises/urlshort/students/latentgenius/handlersb\x06proto3"
+
+var (
+	file_redirects_proto_rawDescOnce sync.Once
+	file_redirects_proto_rawDescData []byte
+)
+
+func file_redirects_proto_rawDescGZIP() []byte {
+	file_redirects_proto_rawDescOnce.Do(func() {
+		file_redirects_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_redirects_proto_rawDesc), len(file_redirects_proto_rawDesc)))
+	})
+	return file_redirects_proto_rawDescData
+}
+
+var file_redirects_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_redirects_proto_goTypes = []any{
+	(*Redirect)(nil),  // 0: urlshort.Redirect
+	(*Redirects)(nil), // 1: urlshort.Redirects
+}
+var file_redirects_proto_depIdxs = []int32{
+	0, // 0: urlshort.Redirects.redirects:type_name -> urlshort.Redirect
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_redirects_proto_init() }
+func file_redirects_proto_init() {
+	if File_redirects_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_redirects_proto_rawDesc), len(file_redirects_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_redirects_proto_goTypes,
+		DependencyIndexes: file_redirects_proto_depIdxs,
+		MessageInfos:      file_redirects_proto_msgTypes,
+	}.Build()
+	File_redirects_proto = out.File
+	file_redirects_proto_goTypes = nil
+	file_redirects_proto_depIdxs = nil
+}
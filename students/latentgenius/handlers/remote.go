@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const sourceRemote = "remote"
+
+// remoteTimeout bounds each fetch of a remote mapping.
+const remoteTimeout = 30 * time.Second
+
+// defaultRemoteInterval is the polling interval used when a
+// non-positive one is given.
+const defaultRemoteInterval = time.Minute
+
+// RemoteHandler will return an http.HandlerFunc serving the mapping
+// published at url, such as an internal config endpoint. The mapping is
+// fetched once up front and then every interval, and is parsed as YAML
+// or JSON according to the response's Content-Type (application/json,
+// or application/yaml, application/x-yaml, text/yaml). The formats are
+// the ones accepted by YAMLHandler and JSONHandler.
+//
+// Each successful fetch atomically replaces the mapping in use. If a
+// fetch or parse fails, the last good mapping keeps being served and
+// the error is logged. Failed fetches are retried with exponential
+// backoff, from interval up to 32 times interval, except that a 429 or
+// 503 response with a Retry-After header is retried when it says, but
+// no later than 32 times interval. Fetches send If-None-Match and
+// If-Modified-Since when the server provided an ETag or Last-Modified,
+// so an unchanged mapping isn't downloaded again. An interval of zero
+// or less means one minute.
+//
+// An error is returned if the initial fetch fails. The returned stop
+// function ends the polling goroutine.
+func RemoteHandler(url string, interval time.Duration, fallback http.Handler, opts ...Option) (http.HandlerFunc, func(), error) {
+	src := &remoteSource{
+		url:    url,
+		client: &http.Client{Timeout: remoteTimeout},
+		parse:  parseByContentType,
+	}
+	return pollRemote(sourceRemote, src, interval, fallback, newOptions(opts))
+}
+
+// pollRemote serves the mapping fetched from src, refetching it every
+// interval, as described for RemoteHandler.
+func pollRemote(source string, src *remoteSource, interval time.Duration, fallback http.Handler, o *options) (http.HandlerFunc, func(), error) {
+	if interval <= 0 {
+		interval = defaultRemoteInterval
+	}
+	m, _, err := src.fetch()
+	if err == nil {
+		err = o.checkEntries(len(m.urls))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var current atomic.Value
+	current.Store(mappingHandler(source, m, fallback, o))
+
+	done := make(chan struct{})
+	var stopOnce sync.Once
+	go func() {
+		wait := interval
+		failures := 0
+		for {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-done:
+				timer.Stop()
+				return
+			}
+			m, changed, err := src.fetch()
+			if err == nil && changed {
+				err = o.checkEntries(len(m.urls))
+			}
+			if err != nil {
+				failures++
+				wait = retryDelay(err, interval, failures)
+				log.Printf("%s %s: %v; retrying in %v", source, src.url, err, wait)
+				continue
+			}
+			failures = 0
+			wait = interval
+			if changed {
+				current.Store(mappingHandler(source, m, fallback, o))
+			}
+		}
+	}()
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		current.Load().(http.HandlerFunc)(w, r)
+	}
+	stop := func() {
+		stopOnce.Do(func() { close(done) })
+	}
+	return h, stop, nil
+}
+
+// remoteSource fetches a mapping over HTTP, remembering the validators
+// of the last response for conditional requests.
+type remoteSource struct {
+	url          string
+	client       *http.Client
+	parse        func(contentType string, data []byte) (*mapping, error)
+	etag         string
+	lastModified string
+}
+
+// fetch downloads and parses the mapping. changed is false, with a nil
+// mapping, if the server answered 304 Not Modified.
+func (s *remoteSource) fetch() (m *mapping, changed bool, err error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if s.etag != "" {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+	if s.lastModified != "" {
+		req.Header.Set("If-Modified-Since", s.lastModified)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, false, nil
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		return nil, false, &throttledError{
+			status: resp.Status,
+			after:  parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	m, err = s.parse(resp.Header.Get("Content-Type"), data)
+	if err != nil {
+		return nil, false, err
+	}
+	s.etag = resp.Header.Get("ETag")
+	s.lastModified = resp.Header.Get("Last-Modified")
+	return m, true, nil
+}
+
+// parseByContentType parses data as JSON or YAML according to the media
+// type contentType.
+func parseByContentType(contentType string, data []byte) (*mapping, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Type %q: %v", contentType, err)
+	}
+	switch mediaType {
+	case "application/json":
+		pathMap, err := parseJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		return &mapping{urls: pathMap}, nil
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		parsedYaml, err := parseYAML(data)
+		if err != nil {
+			return nil, err
+		}
+		return buildMapping(parsedYaml)
+	}
+	return nil, fmt.Errorf("unsupported Content-Type %q", mediaType)
+}
+
+// maxBackoff caps the exponential backoff of a failing poller, as a
+// multiple of its interval.
+const maxBackoff = 32
+
+// maxRetryAfter caps the delay a Retry-After header can ask for, before
+// the poller's own cap of maxBackoff intervals applies.
+const maxRetryAfter = 24 * time.Hour
+
+// throttledError is the error of a fetch answered with 429 or 503.
+type throttledError struct {
+	status string
+	after  time.Duration // from Retry-After; 0 if missing or invalid
+}
+
+func (e *throttledError) Error() string {
+	return "unexpected status " + e.status
+}
+
+// retryDelay returns how long a poller waits after its failures-th
+// consecutive failure, err.
+func retryDelay(err error, interval time.Duration, failures int) time.Duration {
+	if t, ok := err.(*throttledError); ok && t.after > 0 {
+		if t.after > maxBackoff*interval {
+			return maxBackoff * interval
+		}
+		return t.after
+	}
+	backoff := interval
+	for i := 1; i < failures && backoff < maxBackoff*interval; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff*interval {
+		backoff = maxBackoff * interval
+	}
+	return backoff
+}
+
+// parseRetryAfter parses a Retry-After header, in delta-seconds or
+// HTTP-date form, into a delay from now, of at most maxRetryAfter. It
+// returns 0 if the header is missing or invalid, or names a time
+// already past.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		if secs > int(maxRetryAfter/time.Second) {
+			return maxRetryAfter
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil && t.After(now) {
+		if d := t.Sub(now); d < maxRetryAfter {
+			return d
+		}
+		return maxRetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"0", 0},
+		{"-5", 0},
+		{"bogus", 0},
+		{"9223372036", maxRetryAfter},
+		{"99999999999999999999", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Hour).Format(http.TimeFormat), 0},
+		{now.AddDate(10, 0, 0).Format(http.TimeFormat), maxRetryAfter},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	interval := time.Second
+	tests := []struct {
+		name     string
+		err      error
+		failures int
+		want     time.Duration
+	}{
+		{"first failure", errors.New("boom"), 1, time.Second},
+		{"backoff", errors.New("boom"), 3, 4 * time.Second},
+		{"backoff capped", errors.New("boom"), 40, maxBackoff * time.Second},
+		{"retry after", &throttledError{after: 5 * time.Second}, 1, 5 * time.Second},
+		{"retry after capped", &throttledError{after: maxRetryAfter}, 1, maxBackoff * time.Second},
+		{"no retry after", &throttledError{}, 2, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.err, interval, tt.failures); got != tt.want {
+			t.Errorf("%s: retryDelay = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRemoteHandler(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		body   = `{"/a": "https://example.com/v1"}`
+		etag   = `"v1"`
+		cached int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("If-None-Match") == etag {
+			cached++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag)
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+
+	h, stop, err := RemoteHandler(srv.URL, 10*time.Millisecond, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	location := func() string {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/a", nil))
+		return rec.Header().Get("Location")
+	}
+	if got := location(); got != "https://example.com/v1" {
+		t.Fatalf("GET /a redirected to %q, want https://example.com/v1", got)
+	}
+
+	mu.Lock()
+	body, etag = `{"/a": "https://example.com/v2"}`, `"v2"`
+	mu.Unlock()
+	deadline := time.Now().Add(5 * time.Second)
+	for location() != "https://example.com/v2" {
+		if time.Now().After(deadline) {
+			t.Fatal("the changed mapping was never picked up")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	for {
+		mu.Lock()
+		n := cached
+		mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no conditional fetch was answered with 304")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRemoteHandlerInitialError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "<html></html>")
+	}))
+	defer srv.Close()
+	if _, _, err := RemoteHandler(srv.URL, time.Minute, nil); err == nil {
+		t.Error("RemoteHandler over an HTML page succeeded, want an error")
+	}
+}
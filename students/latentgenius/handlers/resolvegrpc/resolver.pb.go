@@ -0,0 +1,186 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: resolver.proto
+
+package resolvegrpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ResolveRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Path to resolve, e.g. "/promo". It may carry a query.
+	Path          string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveRequest) Reset() {
+	*x = ResolveRequest{}
+	mi := &file_resolver_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveRequest) ProtoMessage() {}
+
+func (x *ResolveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_resolver_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveRequest.ProtoReflect.Descriptor instead.
+func (*ResolveRequest) Descriptor() ([]byte, []int) {
+	return file_resolver_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ResolveRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type ResolveResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Destination of the redirect; empty if the path didn't redirect.
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// HTTP status the handler answered with, e.g. 302, or 404 for an
+	// unmapped path.
+	Status        int32 `protobuf:"varint,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveResponse) Reset() {
+	*x = ResolveResponse{}
+	mi := &file_resolver_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveResponse) ProtoMessage() {}
+
+func (x *ResolveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_resolver_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveResponse.ProtoReflect.Descriptor instead.
+func (*ResolveResponse) Descriptor() ([]byte, []int) {
+	return file_resolver_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ResolveResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ResolveResponse) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+var File_resolver_proto protoreflect.FileDescriptor
+
+const file_resolver_proto_rawDesc = "" +
+	"\n" +
+	"\x0eresolver.proto\x12\x10urlshort.resolve\"$\n" +
+	"\x0eResolveRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\";\n" +
+	"\x0fResolveResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\x05R\x06status2Z\n" +
+	"\bResolver\x12N\n" +
+	"\aResolve\x12 .urlshort.resolve.ResolveRequest\x1a!.urlshort.resolve.ResolveResponseBLZJgithub.com/gophercises/urlshort/students/latentgenius/handlers/resolvegrpcb\x06proto3"
+
+var (
+	file_resolver_proto_rawDescOnce sync.Once
+	file_resolver_proto_rawDescData []byte
+)
+
+func file_resolver_proto_rawDescGZIP() []byte {
+	file_resolver_proto_rawDescOnce.Do(func() {
+		file_resolver_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_resolver_proto_rawDesc), len(file_resolver_proto_rawDesc)))
+	})
+	return file_resolver_proto_rawDescData
+}
+
+var file_resolver_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_resolver_proto_goTypes = []any{
+	(*ResolveRequest)(nil),  // 0: urlshort.resolve.ResolveRequest
+	(*ResolveResponse)(nil), // 1: urlshort.resolve.ResolveResponse
+}
+var file_resolver_proto_depIdxs = []int32{
+	0, // 0: urlshort.resolve.Resolver.Resolve:input_type -> urlshort.resolve.ResolveRequest
+	1, // 1: urlshort.resolve.Resolver.Resolve:output_type -> urlshort.resolve.ResolveResponse
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_resolver_proto_init() }
+func file_resolver_proto_init() {
+	if File_resolver_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_resolver_proto_rawDesc), len(file_resolver_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_resolver_proto_goTypes,
+		DependencyIndexes: file_resolver_proto_depIdxs,
+		MessageInfos:      file_resolver_proto_msgTypes,
+	}.Build()
+	File_resolver_proto = out.File
+	file_resolver_proto_goTypes = nil
+	file_resolver_proto_depIdxs = nil
+}
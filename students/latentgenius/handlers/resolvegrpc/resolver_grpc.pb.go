@@ -0,0 +1,116 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: resolver.proto
+
+package resolvegrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	Resolver_Resolve_FullMethodName = "/urlshort.resolve.Resolver/Resolve"
+)
+
+// ResolverClient is the client API for Resolver service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Resolver resolves short paths to their destinations, as the HTTP
+// redirect handlers would.
+type ResolverClient interface {
+	Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error)
+}
+
+type resolverClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewResolverClient(cc grpc.ClientConnInterface) ResolverClient {
+	return &resolverClient{cc}
+}
+
+func (c *resolverClient) Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolveResponse)
+	err := c.cc.Invoke(ctx, Resolver_Resolve_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ResolverServer is the server API for Resolver service.
+// All implementations must embed UnimplementedResolverServer
+// for forward compatibility
+//
+// Resolver resolves short paths to their destinations, as the HTTP
+// redirect handlers would.
+type ResolverServer interface {
+	Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error)
+	mustEmbedUnimplementedResolverServer()
+}
+
+// UnimplementedResolverServer must be embedded to have forward compatible implementations.
+type UnimplementedResolverServer struct {
+}
+
+func (UnimplementedResolverServer) Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resolve not implemented")
+}
+func (UnimplementedResolverServer) mustEmbedUnimplementedResolverServer() {}
+
+// UnsafeResolverServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ResolverServer will
+// result in compilation errors.
+type UnsafeResolverServer interface {
+	mustEmbedUnimplementedResolverServer()
+}
+
+func RegisterResolverServer(s grpc.ServiceRegistrar, srv ResolverServer) {
+	s.RegisterService(&Resolver_ServiceDesc, srv)
+}
+
+func _Resolver_Resolve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResolverServer).Resolve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Resolver_Resolve_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResolverServer).Resolve(ctx, req.(*ResolveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Resolver_ServiceDesc is the grpc.ServiceDesc for Resolver service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Resolver_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "urlshort.resolve.Resolver",
+	HandlerType: (*ResolverServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Resolve",
+			Handler:    _Resolver_Resolve_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "resolver.proto",
+}
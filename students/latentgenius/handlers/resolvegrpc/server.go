@@ -0,0 +1,109 @@
+// Package resolvegrpc serves path resolution over gRPC, for callers that
+// would rather ask where a short path goes than follow an HTTP
+// redirect. It is a separate package so that users of the HTTP handlers
+// alone don't depend on gRPC.
+package resolvegrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative resolver.proto
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gophercises/urlshort/students/latentgenius/handlers"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the Resolver service, either as a read-only lookup
+// in a handlers.Store or on top of an http.Handler.
+type Server struct {
+	UnimplementedResolverServer
+	store handlers.Store
+	h     http.Handler
+}
+
+// NewStoreServer returns a Server looking paths up in store, the Store
+// behind a StoreHandler, and answering as that handler would: 302 with
+// the destination, 404 for a path without a link, 410 for a GoneURL
+// and 451 for a LegalPrefix destination. Lookups have no side effects,
+// so resolving a path over gRPC isn't counted as a redirect. Register
+// it with RegisterResolverServer.
+func NewStoreServer(store handlers.Store) *Server {
+	return &Server{store: store}
+}
+
+// NewServer returns a Server resolving paths with h, typically a chain
+// of the redirect handlers, so resolution over gRPC gives exactly the
+// answer a browser would get, options included. Each Resolve call runs
+// h with a GET request for the path and reports the status and
+// Location it answered with. It is a real request to h, with its side
+// effects: it counts towards rate limits, host budgets and the
+// counters, waits out any jitter and fires webhooks. Use NewStoreServer
+// for a read-only lookup. Register it with RegisterResolverServer.
+func NewServer(h http.Handler) *Server {
+	return &Server{h: h}
+}
+
+// Resolve resolves req.Path. Paths must start with a slash.
+func (s *Server) Resolve(ctx context.Context, req *ResolveRequest) (*ResolveResponse, error) {
+	if !strings.HasPrefix(req.GetPath(), "/") {
+		return nil, status.Errorf(codes.InvalidArgument, "path %q must start with /", req.GetPath())
+	}
+	if s.store != nil {
+		return s.lookup(req.GetPath())
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, req.GetPath(), nil)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid path %q: %v", req.GetPath(), err)
+	}
+	r.RequestURI = req.GetPath()
+	w := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+	s.h.ServeHTTP(w, r)
+	return &ResolveResponse{
+		Url:    w.header.Get("Location"),
+		Status: int32(w.status),
+	}, nil
+}
+
+// lookup resolves path, less any query, in the store.
+func (s *Server) lookup(path string) (*ResolveResponse, error) {
+	path, _, _ = strings.Cut(path, "?")
+	dst, ok, err := s.store.Get(path)
+	switch {
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "looking up %q: %v", path, err)
+	case !ok:
+		return &ResolveResponse{Status: http.StatusNotFound}, nil
+	case dst == handlers.GoneURL:
+		return &ResolveResponse{Status: http.StatusGone}, nil
+	case strings.HasPrefix(dst, handlers.LegalPrefix):
+		return &ResolveResponse{Status: http.StatusUnavailableForLegalReasons}, nil
+	}
+	return &ResolveResponse{Url: dst, Status: http.StatusFound}, nil
+}
+
+// responseRecorder keeps the status and header of a response and
+// discards its body.
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+}
+
+func (w *responseRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *responseRecorder) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return len(b), nil
+}
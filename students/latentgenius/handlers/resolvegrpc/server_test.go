@@ -0,0 +1,50 @@
+package resolvegrpc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gophercises/urlshort/students/latentgenius/handlers"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestResolve(t *testing.T) {
+	links := map[string]string{
+		"/promo":   "https://example.com/promo",
+		"/retired": handlers.GoneURL,
+		"/blocked": handlers.LegalPrefix + "https://example.com/notice",
+	}
+	servers := map[string]*Server{
+		"store":   NewStoreServer(handlers.NewMapStore(links)),
+		"handler": NewServer(handlers.MapHandler(links, nil)),
+	}
+	tests := []struct {
+		path   string
+		url    string
+		status int32
+	}{
+		{"/promo", "https://example.com/promo", http.StatusFound},
+		{"/promo?utm=x", "https://example.com/promo", http.StatusFound},
+		{"/missing", "", http.StatusNotFound},
+		{"/retired", "", http.StatusGone},
+		{"/blocked", "", http.StatusUnavailableForLegalReasons},
+	}
+	for name, s := range servers {
+		for _, tt := range tests {
+			resp, err := s.Resolve(context.Background(), &ResolveRequest{Path: tt.path})
+			if err != nil {
+				t.Errorf("%s: Resolve(%q): %v", name, tt.path, err)
+				continue
+			}
+			if resp.GetUrl() != tt.url || resp.GetStatus() != tt.status {
+				t.Errorf("%s: Resolve(%q) = %q %d, want %q %d", name, tt.path, resp.GetUrl(), resp.GetStatus(), tt.url, tt.status)
+			}
+		}
+		_, err := s.Resolve(context.Background(), &ResolveRequest{Path: "promo"})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: Resolve(\"promo\") error = %v, want InvalidArgument", name, err)
+		}
+	}
+}
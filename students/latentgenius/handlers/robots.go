@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+)
+
+// DefaultRobotsTxt disallows crawling of every path, keeping search
+// engines from spending crawl budget on short links and from indexing
+// the destinations behind them.
+const DefaultRobotsTxt = "User-agent: *\nDisallow: /\n"
+
+// RobotsHandler will return an http.HandlerFunc serving body as a
+// robots.txt file, to be mounted at /robots.txt in front of the
+// redirect handlers. An empty body means DefaultRobotsTxt; teams that
+// do want their links crawled can pass their own rules instead.
+func RobotsHandler(body string) http.HandlerFunc {
+	if body == "" {
+		body = DefaultRobotsTxt
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, body)
+	}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+const sourceRoundRobin = "roundrobin"
+
+// Mirror is one of several destinations a path can be redirected to.
+// A mirror with Weight 2 gets twice the turns of a mirror with Weight
+// 1; weights below 1 count as 1. Name identifies the mirror as a
+// variant, see WithVariantAttribution.
+type Mirror struct {
+	URL    string
+	Weight int
+	Name   string
+}
+
+// rotation is the precomputed redirect order of one path, cycled
+// through by an atomic counter.
+type rotation struct {
+	next     uint64
+	mirrors  []Mirror
+	schedule []int // indexes into mirrors
+}
+
+// RoundRobinHandler will return an http.HandlerFunc that spreads the
+// requests for each path across its mirrors in weighted round-robin
+// order. Unlike a random split, the distribution is exact: over every
+// cycle of sum-of-weights requests, each mirror is chosen exactly
+// Weight times, with the turns interleaved rather than bunched up.
+// Weights are only kept exact while, divided by their greatest common
+// divisor, they add up to at most 10000; larger ones are scaled down
+// to that and split approximately.
+// If the path has no mirrors, then the fallback http.Handler will be
+// called instead.
+func RoundRobinHandler(pathsToMirrors map[string][]Mirror, fallback http.Handler, opts ...Option) http.HandlerFunc {
+	o := newOptions(opts)
+	fallback = o.missFallback(sourceRoundRobin, fallback)
+	rotations := make(map[string]*rotation, len(pathsToMirrors))
+	for path, mirrors := range pathsToMirrors {
+		if len(mirrors) > 0 {
+			rotations[path] = &rotation{mirrors: mirrors, schedule: schedule(mirrors)}
+		}
+	}
+
+	return o.handler(sourceRoundRobin, func(w http.ResponseWriter, r *http.Request) {
+		if o.serveRoot(w, r, sourceRoundRobin, fallback) {
+			return
+		}
+		rot, ok := rotations[r.URL.Path]
+		if !ok {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		n := atomic.AddUint64(&rot.next, 1) - 1
+		i := rot.schedule[n%uint64(len(rot.schedule))]
+		dst := rot.mirrors[i].URL
+		if o.blank(w, r, sourceRoundRobin, dst, fallback) {
+			return
+		}
+		dst = o.markVariant(w, dst, variantOf(rot.mirrors, i))
+		o.redirect(w, r, sourceRoundRobin, dst, http.StatusFound, fallback)
+	})
+}
+
+// maxScheduleLen bounds the length of a rotation's schedule, so that
+// huge weights can't exhaust memory when the handler is built.
+const maxScheduleLen = 10000
+
+// schedule lays out one full cycle of the smooth weighted round-robin
+// order of mirrors, as indexes: at each turn every mirror gains its
+// weight, the mirror with the most is picked and loses the total weight.
+func schedule(mirrors []Mirror) []int {
+	weights := scheduleWeights(mirrors)
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	current := make([]int, len(mirrors))
+	order := make([]int, 0, total)
+	for turn := 0; turn < total; turn++ {
+		best := 0
+		for i := range mirrors {
+			current[i] += weights[i]
+			if current[i] > current[best] {
+				best = i
+			}
+		}
+		current[best] -= total
+		order = append(order, best)
+	}
+	return order
+}
+
+// scheduleWeights returns the weights of mirrors reduced by their
+// greatest common divisor, which keeps the split while shortening the
+// cycle. If they still add up to more than maxScheduleLen, they are
+// scaled down to about that, and the split becomes approximate.
+func scheduleWeights(mirrors []Mirror) []int {
+	weights := make([]int, len(mirrors))
+	d := 0
+	for i, m := range mirrors {
+		weights[i] = m.Weight
+		if weights[i] < 1 {
+			weights[i] = 1
+		}
+		d = gcd(d, weights[i])
+	}
+	total := 0
+	for i := range weights {
+		weights[i] /= d
+		total += weights[i]
+	}
+	if total <= maxScheduleLen {
+		return weights
+	}
+	for i, w := range weights {
+		weights[i] = int(float64(w) * maxScheduleLen / float64(total))
+		if weights[i] < 1 {
+			weights[i] = 1
+		}
+	}
+	return weights
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// redirectCounts sends n concurrent requests for path to h and counts
+// the redirect destinations.
+func redirectCounts(t *testing.T, h http.Handler, path string, n int) map[string]int {
+	t.Helper()
+	var (
+		mu     sync.Mutex
+		counts = make(map[string]int)
+		wg     sync.WaitGroup
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+			mu.Lock()
+			counts[rec.Header().Get("Location")]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return counts
+}
+
+func TestRoundRobinHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		mirrors []Mirror
+		n       int
+		want    map[string]int
+	}{
+		{"even", []Mirror{{URL: "https://a.example"}, {URL: "https://b.example"}, {URL: "https://c.example"}}, 300,
+			map[string]int{"https://a.example": 100, "https://b.example": 100, "https://c.example": 100}},
+		{"weighted", []Mirror{{URL: "https://a.example", Weight: 3}, {URL: "https://b.example", Weight: 1}}, 400,
+			map[string]int{"https://a.example": 300, "https://b.example": 100}},
+		{"common divisor", []Mirror{{URL: "https://a.example", Weight: 2000000}, {URL: "https://b.example", Weight: 1000000}}, 300,
+			map[string]int{"https://a.example": 200, "https://b.example": 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := RoundRobinHandler(map[string][]Mirror{"/dl": tt.mirrors}, nil)
+			counts := redirectCounts(t, h, "/dl", tt.n)
+			for url, want := range tt.want {
+				if counts[url] != want {
+					t.Errorf("%s got %d redirects, want %d (all: %v)", url, counts[url], want, counts)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundRobinMiss(t *testing.T) {
+	h := RoundRobinHandler(map[string][]Mirror{"/dl": {{URL: "https://a.example"}}, "/empty": nil}, nil)
+	for _, path := range []string{"/other", "/empty"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestScheduleBounded(t *testing.T) {
+	s := schedule([]Mirror{{URL: "a", Weight: 1000000000}, {URL: "b", Weight: 999999999}, {URL: "c", Weight: 1}})
+	if len(s) > maxScheduleLen+3 {
+		t.Fatalf("schedule has %d turns, want at most about %d", len(s), maxScheduleLen)
+	}
+	counts := make([]int, 3)
+	for _, i := range s {
+		counts[i]++
+	}
+	if counts[2] < 1 || counts[0] < counts[1] {
+		t.Errorf("turns = %v, want a turn for every mirror and the most for a", counts)
+	}
+}
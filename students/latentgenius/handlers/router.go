@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Router dispatches requests to sub-handlers registered under path
+// prefixes, such as /marketing/ or /eng/, so that each prefix can be
+// backed by its own source (YAML, JSON, DB, ...). If the request path
+// doesn't fall under any registered prefix, the fallback http.Handler
+// will be called instead.
+//
+// Sub-handlers see the full request path, so their mappings should use
+// the prefixed paths (e.g. /marketing/promo). Passing the router's
+// fallback as each sub-handler's fallback keeps a single shared
+// fallback for the whole tree.
+type Router struct {
+	mu       sync.RWMutex
+	prefixes []string
+	handlers map[string]http.Handler
+	fallback http.Handler
+}
+
+// NewRouter returns an empty Router that calls fallback for any
+// request that doesn't match a registered prefix. A nil fallback means
+// http.NotFoundHandler().
+func NewRouter(fallback http.Handler) *Router {
+	return &Router{
+		handlers: make(map[string]http.Handler),
+		fallback: orNotFound(fallback),
+	}
+}
+
+// Handle registers h under prefix. Prefixes must start with a slash
+// and match on whole path segments: /eng/ (or /eng) owns /eng and
+// everything below it, such as /eng/docs, but not /engineering.
+// Prefixes may not overlap: registering /eng/ and /eng/platform/ on the
+// same Router is an error, since it would be ambiguous which one owns
+// /eng/platform/docs, while /eng/ and /engineering/ are unrelated.
+func (rt *Router) Handle(prefix string, h http.Handler) error {
+	if !strings.HasPrefix(prefix, "/") {
+		return fmt.Errorf("router: prefix %q must start with /", prefix)
+	}
+	if h == nil {
+		return fmt.Errorf("router: nil handler for prefix %q", prefix)
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	for _, p := range rt.prefixes {
+		if underPrefix(prefix, p) || underPrefix(p, prefix) {
+			return fmt.Errorf("router: prefix %q overlaps registered prefix %q", prefix, p)
+		}
+	}
+	rt.prefixes = append(rt.prefixes, prefix)
+	rt.handlers[prefix] = h
+	return nil
+}
+
+// ServeHTTP dispatches the request to the sub-handler whose prefix
+// matches the request path, or to the fallback if none does.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt.mu.RLock()
+	var h http.Handler
+	for _, p := range rt.prefixes {
+		if underPrefix(r.URL.Path, p) {
+			h = rt.handlers[p]
+			break
+		}
+	}
+	rt.mu.RUnlock()
+	if h == nil {
+		h = rt.fallback
+	}
+	h.ServeHTTP(w, r)
+}
+
+// underPrefix reports whether path is prefix or lies below it, on a
+// path segment boundary. A trailing slash on prefix makes no
+// difference.
+func underPrefix(path, prefix string) bool {
+	base := strings.TrimSuffix(prefix, "/")
+	return path == base || strings.HasPrefix(path, base+"/")
+}
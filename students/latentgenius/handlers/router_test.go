@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gophercises/urlshort/students/latentgenius/handlers/handlerstest"
+)
+
+func TestRouter(t *testing.T) {
+	home := http.RedirectHandler("https://example.com/", http.StatusSeeOther)
+	rt := NewRouter(home)
+	teams := map[string]map[string]string{
+		"/marketing/":   {"/marketing": "https://example.com/marketing", "/marketing/promo": "https://example.com/promo"},
+		"/eng":          {"/eng/docs": "https://example.com/docs"},
+		"/engineering/": {"/engineering/jobs": "https://example.com/jobs"},
+	}
+	for prefix, pathsToUrls := range teams {
+		if err := rt.Handle(prefix, MapHandler(pathsToUrls, home)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path     string
+		status   int
+		location string
+	}{
+		{"/marketing", http.StatusFound, "https://example.com/marketing"},
+		{"/marketing/promo", http.StatusFound, "https://example.com/promo"},
+		{"/eng/docs", http.StatusFound, "https://example.com/docs"},
+		{"/engineering/jobs", http.StatusFound, "https://example.com/jobs"},
+		// Under a prefix but unmapped: the sub-handler's fallback.
+		{"/eng/missing", http.StatusSeeOther, "https://example.com/"},
+		// Under no prefix: the router's fallback.
+		{"/engine", http.StatusSeeOther, "https://example.com/"},
+		{"/sales/deck", http.StatusSeeOther, "https://example.com/"},
+	}
+	for _, tt := range tests {
+		status, location, _ := handlerstest.ProbeHandler(rt, "GET", tt.path)
+		if status != tt.status || location != tt.location {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.path, status, location, tt.status, tt.location)
+		}
+	}
+}
+
+func TestRouterHandleErrors(t *testing.T) {
+	rt := NewRouter(nil)
+	ok := http.NotFoundHandler()
+	if err := rt.Handle("/eng/", ok); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		prefix string
+		h      http.Handler
+	}{
+		{"/eng/platform/", ok},
+		{"/eng", ok},
+		{"/", ok},
+		{"eng2/", ok},
+		{"/eng2/", nil},
+	}
+	for _, tt := range tests {
+		if err := rt.Handle(tt.prefix, tt.h); err == nil {
+			t.Errorf("Handle(%q) succeeded, want an error", tt.prefix)
+		}
+	}
+	for _, prefix := range []string{"/engineering/", "/en/"} {
+		if err := rt.Handle(prefix, ok); err != nil {
+			t.Errorf("Handle(%q): %v", prefix, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultSchemes are the destination schemes allowed unless
+// WithAllowedSchemes says otherwise.
+var defaultSchemes = map[string]bool{"http": true, "https": true}
+
+// WithAllowedSchemes sets the URL schemes the handler may redirect to,
+// in place of the default of http and https. Destinations with any
+// other scheme, such as javascript:, data: or file:, are refused even
+// if they made it into the mapping, file or database, and a warning is
+// logged; relative destinations, with no scheme, are always allowed.
+// Refused requests fall through to the fallback http.Handler, or get
+// the status set by WithDisallowedSchemeStatus. Schemes are matched
+// case-insensitively.
+func WithAllowedSchemes(schemes ...string) Option {
+	allowed := make(map[string]bool, len(schemes))
+	for _, scheme := range schemes {
+		allowed[strings.ToLower(scheme)] = true
+	}
+	return func(o *options) {
+		o.schemes = allowed
+	}
+}
+
+// WithDisallowedSchemeStatus makes the handler answer requests for a
+// destination with a disallowed scheme with code, e.g. 403 Forbidden,
+// rather than falling through to the fallback.
+func WithDisallowedSchemeStatus(code int) Option {
+	return func(o *options) {
+		o.schemeStatus = code
+	}
+}
+
+// allowedScheme reports whether dst has an allowed scheme; if not, it
+// has logged a warning and answered the request, with fallback unless a
+// status is configured.
+func (o *options) allowedScheme(w http.ResponseWriter, r *http.Request, source, dst string, fallback http.Handler) bool {
+	if o.schemeAllowed(dst) {
+		return true
+	}
+	log.Printf("%s: refusing to redirect %s to %q: scheme not allowed", source, r.URL.Path, dst)
+	if o.schemeStatus != 0 {
+		http.Error(w, http.StatusText(o.schemeStatus), o.schemeStatus)
+		return false
+	}
+	fallback.ServeHTTP(w, r)
+	return false
+}
+
+// schemeAllowed reports whether dst is relative or has an allowed
+// scheme.
+func (o *options) schemeAllowed(dst string) bool {
+	allowed := o.schemes
+	if allowed == nil {
+		allowed = defaultSchemes
+	}
+	u, err := url.Parse(dst)
+	return err == nil && (u.Scheme == "" || allowed[strings.ToLower(u.Scheme)])
+}
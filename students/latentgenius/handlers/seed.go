@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/jinzhu/gorm"
+)
+
+// seedBatchSize is the number of rows SeedDB commits per transaction.
+const seedBatchSize = 1000
+
+// SeedDB inserts pathsToUrls into the urlmaps table used by DBHandler,
+// creating the table if need be. Rows are inserted in sorted path order,
+// in transactions of 1000 rows, and progress, if not nil, is called
+// after each commit with the number of rows loaded so far and the total.
+//
+// If ctx is canceled, or an insert fails (e.g. for a path already in
+// the table), the batch in progress is rolled back and SeedDB returns
+// the error. Batches committed before are kept: the table holds exactly
+// the first loaded paths in sorted order, so a load can be resumed by
+// seeding the remaining ones.
+func SeedDB(ctx context.Context, db *gorm.DB, pathsToUrls map[string]string, progress func(loaded, total int)) error {
+	if err := db.AutoMigrate(&urlmap{}).Error; err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(pathsToUrls))
+	for path := range pathsToUrls {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for loaded := 0; loaded < len(paths); {
+		end := loaded + seedBatchSize
+		if end > len(paths) {
+			end = len(paths)
+		}
+		if err := seedBatch(ctx, db, pathsToUrls, paths[loaded:end]); err != nil {
+			return err
+		}
+		loaded = end
+		if progress != nil {
+			progress(loaded, len(paths))
+		}
+	}
+	return nil
+}
+
+// seedBatch inserts the given paths in one transaction.
+func seedBatch(ctx context.Context, db *gorm.DB, pathsToUrls map[string]string, paths []string) error {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Create(&urlmap{Shortpath: path, URL: pathsToUrls[path]}).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("seeding %s: %v", path, err)
+		}
+	}
+	return tx.Commit().Error
+}
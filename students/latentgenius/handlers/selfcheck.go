@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// SelfCheck sends h a GET request for canaryPath, as a client would,
+// and returns an error unless h redirects it to expectedURL. Run at
+// startup, it catches a misconfigured source, such as an unreachable
+// database or a mapping file that failed to load into the expected
+// shape, before the instance takes traffic. The request goes through
+// the whole handler chain, so it counts in Counters and webhook events
+// like any other; pick a canary path without a rate limit.
+func SelfCheck(h http.Handler, canaryPath, expectedURL string) error {
+	r, err := http.NewRequest(http.MethodGet, canaryPath, nil)
+	if err != nil {
+		return fmt.Errorf("self-check: invalid canary path %q: %v", canaryPath, err)
+	}
+	r.RequestURI = canaryPath
+	w := &checkWriter{header: make(http.Header)}
+	h.ServeHTTP(w, r)
+	if w.status < 300 || w.status > 399 {
+		return fmt.Errorf("self-check: %s answered %d, want a redirect to %s", canaryPath, w.status, expectedURL)
+	}
+	if got := w.header.Get("Location"); got != expectedURL {
+		return fmt.Errorf("self-check: %s redirected to %q, want %q", canaryPath, got, expectedURL)
+	}
+	return nil
+}
+
+// checkWriter is the http.ResponseWriter of SelfCheck and
+// PreviewHandler. It keeps the header and status and discards the body.
+type checkWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *checkWriter) Header() http.Header { return w.header }
+
+func (w *checkWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *checkWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return len(b), nil
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// CheckSelfReferences reports the mappings in pathsToUrls whose
+// destination points back at one of the shortener's own redirect
+// paths, which would send clients around in a loop at the HTTP level.
+// Since the package can't infer the host it is served on, host gives
+// it, e.g. "short.example"; a host without a port matches any port.
+// Relative destinations always point at the shortener.
+//
+// The returned error lists every offending path, in sorted order;
+// callers can refuse the mapping or just log it as a warning. It is nil
+// if there are none.
+func CheckSelfReferences(pathsToUrls map[string]string, host string) error {
+	var loops []string
+	for path, dst := range pathsToUrls {
+		u, err := url.Parse(dst)
+		if err != nil || !pointsAt(u, host) {
+			continue
+		}
+		if _, ok := pathsToUrls[u.Path]; ok {
+			loops = append(loops, fmt.Sprintf("%s -> %s", path, dst))
+		}
+	}
+	if len(loops) == 0 {
+		return nil
+	}
+	sort.Strings(loops)
+	return fmt.Errorf("destinations point back at the shortener: %s", strings.Join(loops, ", "))
+}
+
+// pointsAt reports whether u refers to host: either u is relative, or
+// its host is host, ignoring case and, if host has none, the port.
+func pointsAt(u *url.URL, host string) bool {
+	if u.Scheme == "" && u.Host == "" {
+		return true
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	if strings.Contains(host, ":") {
+		return strings.EqualFold(u.Host, host)
+	}
+	return strings.EqualFold(u.Hostname(), host)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ShardFunc maps a path to the index of the database shard holding it.
+type ShardFunc func(path string) int
+
+// HashShard returns a ShardFunc spreading paths over n shards by the
+// FNV-1a hash of the path.
+func HashShard(n int) ShardFunc {
+	return func(path string) int {
+		h := fnv.New32a()
+		h.Write([]byte(path))
+		return int(h.Sum32() % uint32(n))
+	}
+}
+
+// ShardedDBHandler is like DBHandler for a links table sharded across
+// several databases: each request is looked up in shards[shard(path)]
+// only. A nil shard means HashShard(len(shards)). As with DBHandler,
+// concurrent requests for the same path share a single query.
+//
+// A path missing from its shard falls through to the fallback, as with
+// DBHandler. A shard that can't be queried, or a shard index out of
+// range, is answered with 503 Service Unavailable rather than falling
+// through, so an outage isn't mistaken for missing links.
+func ShardedDBHandler(shards []*gorm.DB, shard ShardFunc, fallback http.Handler, opts ...Option) (http.HandlerFunc, error) {
+	if len(shards) == 0 {
+		return nil, fmt.Errorf("sharded db: no shards")
+	}
+	if shard == nil {
+		shard = HashShard(len(shards))
+	}
+	o := newOptions(opts)
+	fallback = o.missFallback(sourceDB, fallback)
+	for i, db := range shards {
+		if err := db.AutoMigrate(&urlmap{}).Error; err != nil {
+			log.Printf("Gorm error on shard %d: %v", i, err)
+		}
+	}
+	var lookups lookupGroup
+
+	return o.handler(sourceDB, func(w http.ResponseWriter, r *http.Request) {
+		if o.serveRoot(w, r, sourceDB, fallback) {
+			return
+		}
+		i := shard(r.URL.Path)
+		if i < 0 || i >= len(shards) {
+			log.Printf("sharded db: path %s maps to shard %d of %d", r.URL.Path, i, len(shards))
+			http.Error(w, "503 service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		dst, err := lookups.do(r.URL.Path, func() (urlmap, error) {
+			return lookupURL(shards[i], r.URL.Path)
+		})
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				fallback.ServeHTTP(w, r)
+			} else {
+				log.Printf("sharded db: shard %d: %v", i, err)
+				http.Error(w, "503 service unavailable", http.StatusServiceUnavailable)
+			}
+			return
+		}
+		if o.blank(w, r, sourceDB, dst.URL, fallback) {
+			return
+		}
+		o.redirect(w, r, sourceDB, dst.URL, http.StatusMovedPermanently, fallback)
+	}), nil
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// maxCreateAttempts is how many random codes Shortener.Create draws
+// before giving up.
+const maxCreateAttempts = 10
+
+// Shortener creates short links with random codes in a Store, such as
+// a DBStore over the urlmaps table served by DBHandler.
+type Shortener struct {
+	store  Store
+	issued *IssuedCodes
+}
+
+// NewShortener returns a Shortener adding links to store. If issued
+// isn't nil, codes it has issued are never drawn again, and every new
+// code is recorded in it, so codes of deleted links aren't reused.
+// Codes are recorded before their link is written, so a write that
+// fails still uses up its code.
+func NewShortener(store Store, issued *IssuedCodes) *Shortener {
+	return &Shortener{store: store, issued: issued}
+}
+
+// CreateOption configures a Shortener.Create call.
+type CreateOption func(*createOptions)
+
+type createOptions struct {
+	length   int
+	alphabet string
+}
+
+// CodeLength makes Create draw codes of length digits instead of
+// DefaultCodeLength.
+func CodeLength(length int) CreateOption {
+	return func(o *createOptions) {
+		o.length = length
+	}
+}
+
+// CodeAlphabet makes Create draw the digits of codes from alphabet,
+// e.g. CrockfordBase32, instead of base62.
+func CodeAlphabet(alphabet string) CreateOption {
+	return func(o *createOptions) {
+		o.alphabet = alphabet
+	}
+}
+
+// Create adds a link to longURL, an absolute http or https URL, under a
+// new random short path, and returns the path. A drawn code that is
+// already mapped, or issued, is drawn again, up to 10 times; after that
+// an error suggests a longer code, as the code space is getting full.
+// Links are written with Store.PutIfAbsent, so a code taken by a
+// concurrent Create is drawn again as well rather than overwritten.
+func (s *Shortener) Create(longURL string, opts ...CreateOption) (string, error) {
+	o := createOptions{length: DefaultCodeLength, alphabet: base62}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.length <= 0 {
+		return "", fmt.Errorf("invalid code length %d", o.length)
+	}
+	digits, err := alphabetDigits(o.alphabet)
+	if err != nil {
+		return "", err
+	}
+	if err := checkLinkURL(longURL); err != nil {
+		return "", err
+	}
+	for i := 0; i < maxCreateAttempts; i++ {
+		path := "/" + randomCode(digits, o.length)
+		if s.issued != nil {
+			if s.issued.Issued(path) {
+				continue
+			}
+			if err := s.issued.Issue(path); err != nil {
+				if s.issued.Issued(path) {
+					continue
+				}
+				return "", err
+			}
+		}
+		err := s.store.PutIfAbsent(path, longURL)
+		if err == ErrLinkExists {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+	return "", fmt.Errorf("no free code of length %d after %d attempts; use a longer code", o.length, maxCreateAttempts)
+}
+
+// checkLinkURL returns an error unless longURL is an absolute http or
+// https URL.
+func checkLinkURL(longURL string) error {
+	if u, err := url.Parse(longURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q: want an absolute http or https URL", longURL)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// collidingStore is a Store whose first collisions PutIfAbsent calls
+// report the path as taken.
+type collidingStore struct {
+	Store
+	collisions int
+	calls      int
+}
+
+func (s *collidingStore) PutIfAbsent(path, url string) error {
+	s.calls++
+	if s.calls <= s.collisions {
+		return ErrLinkExists
+	}
+	return s.Store.PutIfAbsent(path, url)
+}
+
+func TestShortenerCreate(t *testing.T) {
+	store := &collidingStore{Store: NewMapStore(nil), collisions: 3}
+	path, err := NewShortener(store, nil).Create("https://example.com/long", CodeLength(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.calls != 4 {
+		t.Errorf("PutIfAbsent called %d times, want 4", store.calls)
+	}
+	if len(path) != 6 || !strings.HasPrefix(path, "/") {
+		t.Errorf("Create = %q, want a slash and 5 digits", path)
+	}
+	if url, ok, _ := store.Get(path); !ok || url != "https://example.com/long" {
+		t.Errorf("Get(%s) = %q, %v, want https://example.com/long", path, url, ok)
+	}
+}
+
+func TestShortenerCreateErrors(t *testing.T) {
+	full := &collidingStore{Store: NewMapStore(nil), collisions: maxCreateAttempts}
+	if _, err := NewShortener(full, nil).Create("https://example.com/"); err == nil || !strings.Contains(err.Error(), "longer code") {
+		t.Errorf("Create in a full store = %v, want an error suggesting a longer code", err)
+	}
+	if full.calls != maxCreateAttempts {
+		t.Errorf("PutIfAbsent called %d times, want %d", full.calls, maxCreateAttempts)
+	}
+
+	if _, err := NewShortener(&FileStore{}, nil).Create("https://example.com/"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Create in a read-only store = %v, want ErrReadOnly", err)
+	}
+	if _, err := NewShortener(NewMapStore(nil), nil).Create("ftp://example.com/"); err == nil {
+		t.Error("Create of an ftp URL succeeded")
+	}
+}
+
+func TestShortenerSkipsIssued(t *testing.T) {
+	issued, err := OpenIssuedCodes(filepath.Join(t.TempDir(), "issued"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer issued.Close()
+	s := NewShortener(NewMapStore(nil), issued)
+	path, err := s.Create("https://example.com/", CodeLength(1), CodeAlphabet("ab"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !issued.Issued(path) {
+		t.Errorf("%s wasn't recorded as issued", path)
+	}
+	// Only one other code of one digit in "ab" is left.
+	other, err := s.Create("https://example.com/", CodeLength(1), CodeAlphabet("ab"))
+	if err == nil && other == path {
+		t.Errorf("Create reissued %s", path)
+	}
+}
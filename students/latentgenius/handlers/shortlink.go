@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WithShortlink makes the handler add a header such as
+//
+//	Link: <https://short.example/promo>; rel="shortlink"
+//
+// to its redirects, naming the canonical short URL of the matched path
+// on base, the shortener's public address (e.g. https://short.example).
+// For the map, YAML and JSON handlers that is the mapped path, so a
+// request for /PROMO matching an ignore_case /promo entry advertises
+// /promo; other handlers name the request path. It is off by default.
+func WithShortlink(base string) Option {
+	base = strings.TrimSuffix(base, "/")
+	return func(o *options) {
+		o.shortlinkBase = base
+	}
+}
+
+// shortlinkKey is the context key of the mapped path a request matched.
+type shortlinkKey struct{}
+
+// withShortlinkPath returns r noting path, the mapped path it matched,
+// for setShortlink to name instead of the request path.
+func (o *options) withShortlinkPath(r *http.Request, path string) *http.Request {
+	if o.shortlinkBase == "" {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), shortlinkKey{}, path))
+}
+
+func (o *options) setShortlink(w http.ResponseWriter, r *http.Request) {
+	if o.shortlinkBase == "" {
+		return
+	}
+	link := r.URL.EscapedPath()
+	if path, ok := r.Context().Value(shortlinkKey{}).(string); ok {
+		// Strict entries are keyed by path and query.
+		path, query, _ := strings.Cut(path, "?")
+		link = (&url.URL{Path: path, RawQuery: query}).String()
+	}
+	w.Header().Add("Link", "<"+o.shortlinkBase+link+`>; rel="shortlink"`)
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestShortlink(t *testing.T) {
+	yaml := `
+- path: /promo
+  url: https://example.com/promo
+  ignore_case: true
+- path: /docs
+  url: https://example.com/docs
+- path: /a b
+  url: https://example.com/space
+`
+	tests := []struct {
+		name   string
+		opts   []Option
+		target string
+		want   string
+	}{
+		{"exact", nil, "/docs", "https://short.example/docs"},
+		{"ignore case", nil, "/PROMO", "https://short.example/promo"},
+		{"escaped", nil, "/a%20b", "https://short.example/a%20b"},
+		{"parent", []Option{WithParentFallback()}, "/docs/install", "https://short.example/docs"},
+		{"canonical", []Option{WithCanonicalize(func(p string) string {
+			return strings.TrimSuffix(p, "/")
+		})}, "/docs/", "https://short.example/docs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithShortlink("https://short.example/")}, tt.opts...)
+			h, err := YAMLHandler([]byte(yaml), nil, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", tt.target, nil))
+			if rec.Code != http.StatusFound {
+				t.Fatalf("GET %s = %d, want %d", tt.target, rec.Code, http.StatusFound)
+			}
+			if got, want := rec.Header().Get("Link"), "<"+tt.want+`>; rel="shortlink"`; got != want {
+				t.Errorf("Link = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestShortlinkOff(t *testing.T) {
+	rec := httptest.NewRecorder()
+	MapHandler(map[string]string{"/a": "https://example.com/a"}, nil).ServeHTTP(rec, httptest.NewRequest("GET", "/a", nil))
+	if got := rec.Header().Get("Link"); got != "" {
+		t.Errorf("Link = %q, want none", got)
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"html"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// slugFetchTimeout bounds the fetch of a destination by SuggestSlug.
+	slugFetchTimeout = 5 * time.Second
+	// slugFetchLimit is how much of a destination SuggestSlug reads
+	// looking for its title.
+	slugFetchLimit = 64 << 10
+	// maxSlugLength caps the length of a slug made from a title.
+	maxSlugLength = 48
+	// maxSlugSuffix is the highest numeric suffix SuggestSlug tries on a
+	// taken slug before giving up on the title.
+	maxSlugSuffix = 100
+)
+
+// SuggestSlug suggests a short path for dst made from the title of the
+// page, so /how-to-bake-bread can stand for a recipe: dst is fetched,
+// with a 5s timeout and reading at most 64KB, and the text of its
+// <title> is lowercased and reduced to words joined by hyphens. If the
+// page can't be fetched or has no usable title, the suggestion is a
+// random code of DefaultCodeLength base62 digits instead.
+//
+// taken, if not nil, reports whether a path is already mapped; a taken
+// slug gets a numeric suffix (/how-to-bake-bread-2), and taken random
+// codes are drawn again, up to 10 times. If every candidate is taken,
+// as with a taken that reports true on errors, the suggestion is "".
+func SuggestSlug(ctx context.Context, dst string, taken func(path string) bool) string {
+	if taken == nil {
+		taken = func(string) bool { return false }
+	}
+	if slug := slugify(fetchTitle(ctx, dst)); slug != "" {
+		if path := "/" + slug; !taken(path) {
+			return path
+		}
+		for n := 2; n <= maxSlugSuffix; n++ {
+			if path := "/" + slug + "-" + strconv.Itoa(n); !taken(path) {
+				return path
+			}
+		}
+	}
+	digits := []rune(base62)
+	for i := 0; i < maxCreateAttempts; i++ {
+		if path := "/" + randomCode(digits, DefaultCodeLength); !taken(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// fetchTitle returns the title of the page at url, or "" if it can't
+// be had.
+func fetchTitle(ctx context.Context, url string) string {
+	ctx, cancel := context.WithTimeout(ctx, slugFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return ""
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	page, err := io.ReadAll(io.LimitReader(resp.Body, slugFetchLimit))
+	if err != nil {
+		return ""
+	}
+	lower := bytes.ToLower(page)
+	start := bytes.Index(lower, []byte("<title"))
+	if start < 0 {
+		return ""
+	}
+	open := bytes.IndexByte(lower[start:], '>')
+	if open < 0 {
+		return ""
+	}
+	start += open + 1
+	end := bytes.Index(lower[start:], []byte("</title"))
+	if end < 0 {
+		return ""
+	}
+	return html.UnescapeString(string(page[start : start+end]))
+}
+
+// slugify reduces title to its lowercased letters and digits, with
+// words joined by hyphens, cut at a word boundary to maxSlugLength.
+func slugify(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	slug := ""
+	for _, word := range words {
+		next := word
+		if slug != "" {
+			next = slug + "-" + word
+		}
+		if len(next) > maxSlugLength {
+			break
+		}
+		slug = next
+	}
+	return slug
+}
+
+// randomCode returns a random code of the given length with digits
+// from alphabet.
+func randomCode(alphabet []rune, length int) string {
+	radix := big.NewInt(int64(len(alphabet)))
+	code := make([]rune, length)
+	for i := range code {
+		digit, err := rand.Int(rand.Reader, radix)
+		if err != nil {
+			panic(err)
+		}
+		code[i] = alphabet[digit.Int64()]
+	}
+	return string(code)
+}
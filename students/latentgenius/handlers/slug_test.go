@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title, want string
+	}{
+		{"How to Bake Bread", "how-to-bake-bread"},
+		{"  Go 1.22: What's New?  ", "go-1-22-what-s-new"},
+		{"Crème brûlée", "crème-brûlée"},
+		{"!!!", ""},
+		{"a very long title that goes on and on well past the limit of slugs", "a-very-long-title-that-goes-on-and-on-well-past"},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.title); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestSlug(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/recipe" {
+			io.WriteString(w, "<html><head><title>How to Bake Bread &amp; Rolls</title></head></html>")
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	if got := SuggestSlug(ctx, srv.URL+"/recipe", nil); got != "/how-to-bake-bread-rolls" {
+		t.Errorf("SuggestSlug = %q, want /how-to-bake-bread-rolls", got)
+	}
+	taken := map[string]bool{"/how-to-bake-bread-rolls": true, "/how-to-bake-bread-rolls-2": true}
+	if got := SuggestSlug(ctx, srv.URL+"/recipe", func(p string) bool { return taken[p] }); got != "/how-to-bake-bread-rolls-3" {
+		t.Errorf("SuggestSlug with taken slugs = %q, want /how-to-bake-bread-rolls-3", got)
+	}
+	if got := SuggestSlug(ctx, srv.URL+"/untitled", nil); len(got) != 1+DefaultCodeLength {
+		t.Errorf("SuggestSlug of an untitled page = %q, want a random code", got)
+	}
+	if got := SuggestSlug(ctx, srv.URL+"/recipe", func(string) bool { return true }); got != "" {
+		t.Errorf("SuggestSlug with everything taken = %q, want none", got)
+	}
+}
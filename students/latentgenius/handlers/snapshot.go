@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const sourceSnapshot = "snapshot"
+
+// snapshotPageSize is the number of rows read per query while loading a
+// snapshot.
+const snapshotPageSize = 1000
+
+// DBSnapshot is an http.Handler serving redirects from an in-memory
+// snapshot of the urlmaps table used by DBHandler, for read-heavy
+// deployments whose links change only occasionally. Lookups never hit
+// the database; instead the whole table is reloaded every interval and
+// swapped in atomically.
+type DBSnapshot struct {
+	db       *gorm.DB
+	fallback http.Handler
+	o        *options
+	current  atomic.Value // of http.HandlerFunc
+	mu       sync.Mutex   // serializes refreshes
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// SnapshotDBHandler loads the urlmaps table into memory and returns a
+// DBSnapshot serving it, reloading it every interval. The table is read
+// in pages, so large tables don't need one huge query. If a reload
+// fails, the previous snapshot keeps being served and the error is
+// logged. An error is returned if the initial load fails. A
+// non-positive interval turns off the periodic reloading, leaving the
+// snapshot to be reloaded with Refresh.
+func SnapshotDBHandler(db *gorm.DB, interval time.Duration, fallback http.Handler, opts ...Option) (*DBSnapshot, error) {
+	if err := db.AutoMigrate(&urlmap{}).Error; err != nil {
+		log.Println("Gorm error: ", err)
+	}
+	s := &DBSnapshot{
+		db:       db,
+		fallback: fallback,
+		o:        newOptions(opts),
+		done:     make(chan struct{}),
+	}
+	if err := s.Refresh(); err != nil {
+		return nil, err
+	}
+	if interval > 0 {
+		go s.run(interval)
+	}
+	return s, nil
+}
+
+// ServeHTTP redirects the request according to the current snapshot.
+func (s *DBSnapshot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.current.Load().(http.HandlerFunc)(w, r)
+}
+
+// Refresh reloads the snapshot right away, e.g. after a link is known to
+// have changed, rather than waiting for the next interval.
+func (s *DBSnapshot) Refresh() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pathMap, err := loadURLMaps(s.db)
+	if err != nil {
+		return err
+	}
+	s.current.Store(mapHandler(sourceSnapshot, pathMap, s.fallback, s.o))
+	return nil
+}
+
+// Stop ends the periodic reloading.
+func (s *DBSnapshot) Stop() {
+	s.stopOnce.Do(func() { close(s.done) })
+}
+
+func (s *DBSnapshot) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+		if err := s.Refresh(); err != nil {
+			log.Printf("snapshot: %v", err)
+		}
+	}
+}
+
+// loadURLMaps reads the whole urlmaps table, a page at a time, keyed by
+// shortpath so pages stay consistent as rows are added.
+func loadURLMaps(db *gorm.DB) (map[string]string, error) {
+	pathMap := make(map[string]string)
+	last := ""
+	for {
+		var rows []urlmap
+		err := db.Where("shortpath > ?", last).
+			Order("shortpath").
+			Limit(snapshotPageSize).
+			Find(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			pathMap[row.Shortpath] = row.URL
+		}
+		if len(rows) < snapshotPageSize {
+			return pathMap, nil
+		}
+		last = rows[len(rows)-1].Shortpath
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import "net/http"
+
+// StatusWriter is an http.ResponseWriter recording the status code
+// written through it, so middleware can tell what a handler answered,
+// including a fallback at the end of a chain that calls WriteHeader
+// itself:
+//
+//	sw := handlers.NewStatusWriter(w)
+//	next.ServeHTTP(sw, r)
+//	if sw.Status() == http.StatusNotFound {
+//		log.Printf("no mapping for %s", r.URL.Path)
+//	}
+//
+// The handlers use it for tracing and decision recording.
+type StatusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// NewStatusWriter returns a StatusWriter writing to w.
+func NewStatusWriter(w http.ResponseWriter) *StatusWriter {
+	return &StatusWriter{ResponseWriter: w}
+}
+
+// WriteHeader records code, if it is the first status written, and
+// passes it on.
+func (sw *StatusWriter) WriteHeader(code int) {
+	if sw.status == 0 {
+		sw.status = code
+	}
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *StatusWriter) Write(b []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+// Flush flushes the underlying writer, if it supports flushing.
+func (sw *StatusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (sw *StatusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// Status returns the status code written so far, http.StatusOK if only
+// a body was written, or 0 if nothing was.
+func (sw *StatusWriter) Status() int {
+	return sw.status
+}
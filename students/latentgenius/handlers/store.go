@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/jinzhu/gorm"
+)
+
+const sourceStore = "store"
+
+// ErrLinkNotFound is returned by Store.Delete and Store.Replace for a
+// path without a link.
+var ErrLinkNotFound = errors.New("link not found")
+
+// ErrLinkExists is returned by Store.PutIfAbsent for a path that
+// already has a link.
+var ErrLinkExists = errors.New("link already exists")
+
+// ErrReadOnly is returned by the write methods of a read-only Store.
+var ErrReadOnly = errors.New("store is read-only")
+
+// Store is a backend holding links, served by StoreHandler and managed
+// through AdminAPI. The package ships MapStore, DBStore and FileStore;
+// other backends only need to implement these methods, safely for
+// concurrent use.
+type Store interface {
+	// Get returns the destination of path, with ok false if path has
+	// no link.
+	Get(path string) (url string, ok bool, err error)
+	// Put links path to url, replacing any link path already has.
+	Put(path, url string) error
+	// PutIfAbsent links path to url, returning ErrLinkExists if path
+	// already has a link. The check and the write are atomic, so of two
+	// concurrent calls for one path only one succeeds.
+	PutIfAbsent(path, url string) error
+	// Replace relinks path to url, returning ErrLinkNotFound if path
+	// has no link, atomically like PutIfAbsent.
+	Replace(path, url string) error
+	// Delete removes the link of path, returning ErrLinkNotFound if
+	// there is none.
+	Delete(path string) error
+	// List returns all links, sorted by path.
+	List() ([]Link, error)
+}
+
+// StoreHandler will return an http.HandlerFunc that redirects each
+// path with a link in store to its destination, looking it up on every
+// request, so changes to store take effect right away. Paths without a
+// link fall through to the fallback http.Handler; a lookup that fails
+// is answered with 500 Internal Server Error.
+//
+// The per-entry settings of YAML files (status, ignore_case,
+// rate_limit, ...) and options that need the whole mapping up front,
+// like WithCanonicalize, WithParentFallback and WithVersionParam, only
+// work with the handlers built on a static mapping, such as MapHandler
+// and YAMLHandler.
+func StoreHandler(store Store, fallback http.Handler, opts ...Option) http.HandlerFunc {
+	return storeHandler(sourceStore, store, http.StatusFound, fallback, newOptions(opts))
+}
+
+// storeHandler is the handler behind StoreHandler and DBHandler, which
+// redirects with code.
+func storeHandler(source string, store Store, code int, fallback http.Handler, o *options) http.HandlerFunc {
+	fallback = o.missFallback(source, fallback)
+
+	return o.handler(source, func(w http.ResponseWriter, r *http.Request) {
+		if o.serveRoot(w, r, source, fallback) {
+			return
+		}
+		dst, ok, err := store.Get(r.URL.Path)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Unexpected error: %s", err), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		if o.blank(w, r, source, dst, fallback) {
+			return
+		}
+		o.setMatchedRule(w, r.URL.Path)
+		o.redirect(w, r, source, dst, code, fallback)
+	})
+}
+
+// MapStore is a Store kept in memory.
+type MapStore struct {
+	mu    sync.RWMutex
+	links map[string]string
+}
+
+// NewMapStore returns a MapStore holding a copy of pathsToUrls, which
+// may be nil.
+func NewMapStore(pathsToUrls map[string]string) *MapStore {
+	links := make(map[string]string, len(pathsToUrls))
+	for path, url := range pathsToUrls {
+		links[path] = url
+	}
+	return &MapStore{links: links}
+}
+
+// Get implements Store.
+func (s *MapStore) Get(path string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	url, ok := s.links[path]
+	return url, ok, nil
+}
+
+// Put implements Store.
+func (s *MapStore) Put(path, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.links[path] = url
+	return nil
+}
+
+// PutIfAbsent implements Store.
+func (s *MapStore) PutIfAbsent(path, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.links[path]; ok {
+		return ErrLinkExists
+	}
+	s.links[path] = url
+	return nil
+}
+
+// Replace implements Store.
+func (s *MapStore) Replace(path, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.links[path]; !ok {
+		return ErrLinkNotFound
+	}
+	s.links[path] = url
+	return nil
+}
+
+// Delete implements Store.
+func (s *MapStore) Delete(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.links[path]; !ok {
+		return ErrLinkNotFound
+	}
+	delete(s.links, path)
+	return nil
+}
+
+// List implements Store.
+func (s *MapStore) List() ([]Link, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedLinks(s.links), nil
+}
+
+// DBStore is a Store in the urlmaps table of a gorm database, the one
+// DBHandler serves. Concurrent Gets of the same path share a single
+// query.
+type DBStore struct {
+	db      *gorm.DB
+	lookups lookupGroup
+}
+
+// NewDBStore returns a DBStore over db, creating or migrating the
+// urlmaps table as needed.
+func NewDBStore(db *gorm.DB) (*DBStore, error) {
+	if err := db.AutoMigrate(&urlmap{}).Error; err != nil {
+		return nil, err
+	}
+	return &DBStore{db: db}, nil
+}
+
+// Get implements Store.
+func (s *DBStore) Get(path string) (string, bool, error) {
+	row, err := s.lookups.do(path, func() (urlmap, error) {
+		return lookupURL(s.db, path)
+	})
+	if err == gorm.ErrRecordNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return row.URL, true, nil
+}
+
+// Put implements Store. The link is updated in place if path has one,
+// and created otherwise, in one transaction.
+func (s *DBStore) Put(path, url string) error {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	err := applyDBOp(tx, DBOp{Kind: DBUpdate, Path: path, URL: url})
+	if err == gorm.ErrRecordNotFound {
+		err = applyDBOp(tx, DBOp{Kind: DBCreate, Path: path, URL: url})
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// PutIfAbsent implements Store, relying on the unique index on the
+// path.
+func (s *DBStore) PutIfAbsent(path, url string) error {
+	err := applyDBOp(s.db, DBOp{Kind: DBCreate, Path: path, URL: url})
+	if err != nil {
+		// The insert fails for other reasons too; only an existing row
+		// makes it a conflict.
+		if _, lerr := lookupURL(s.db, path); lerr == nil {
+			return ErrLinkExists
+		}
+	}
+	return err
+}
+
+// Replace implements Store, checking and updating in one transaction.
+func (s *DBStore) Replace(path, url string) error {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	err := applyDBOp(tx, DBOp{Kind: DBUpdate, Path: path, URL: url})
+	if err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return ErrLinkNotFound
+		}
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// Delete implements Store.
+func (s *DBStore) Delete(path string) error {
+	err := applyDBOp(s.db, DBOp{Kind: DBDelete, Path: path})
+	if err == gorm.ErrRecordNotFound {
+		return ErrLinkNotFound
+	}
+	return err
+}
+
+// List implements Store.
+func (s *DBStore) List() ([]Link, error) {
+	var rows []urlmap
+	if err := s.db.Order("shortpath").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	links := make([]Link, len(rows))
+	for i, row := range rows {
+		links[i] = Link{Path: row.Shortpath, URL: row.URL}
+	}
+	return links, nil
+}
+
+// FileStore is a read-only Store of the links in a mapping file.
+type FileStore struct {
+	links map[string]string
+}
+
+// NewFileStore returns a FileStore of the mapping file name, read once,
+// here, as by LoadFile.
+func NewFileStore(name string) (*FileStore, error) {
+	links, err := LoadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{links: links}, nil
+}
+
+// Get implements Store.
+func (s *FileStore) Get(path string) (string, bool, error) {
+	url, ok := s.links[path]
+	return url, ok, nil
+}
+
+// Put implements Store; it returns ErrReadOnly.
+func (s *FileStore) Put(path, url string) error {
+	return ErrReadOnly
+}
+
+// PutIfAbsent implements Store; it returns ErrReadOnly.
+func (s *FileStore) PutIfAbsent(path, url string) error {
+	return ErrReadOnly
+}
+
+// Replace implements Store; it returns ErrReadOnly.
+func (s *FileStore) Replace(path, url string) error {
+	return ErrReadOnly
+}
+
+// Delete implements Store; it returns ErrReadOnly.
+func (s *FileStore) Delete(path string) error {
+	return ErrReadOnly
+}
+
+// List implements Store.
+func (s *FileStore) List() ([]Link, error) {
+	return sortedLinks(s.links), nil
+}
+
+// sortedLinks returns the links of pathsToUrls sorted by path.
+func sortedLinks(pathsToUrls map[string]string) []Link {
+	links := make([]Link, 0, len(pathsToUrls))
+	for path, url := range pathsToUrls {
+		links = append(links, Link{Path: path, URL: url})
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Path < links[j].Path })
+	return links
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestStoreConditionalWrites(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"map": func(t *testing.T) Store {
+			return NewMapStore(nil)
+		},
+		"db": func(t *testing.T) Store {
+			store, err := NewDBStore(openTestDB(t, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return store
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			if err := store.Replace("/a", "https://example.com/1"); err != ErrLinkNotFound {
+				t.Errorf("Replace of a missing link = %v, want ErrLinkNotFound", err)
+			}
+			if err := store.PutIfAbsent("/a", "https://example.com/1"); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.PutIfAbsent("/a", "https://example.com/2"); err != ErrLinkExists {
+				t.Errorf("second PutIfAbsent = %v, want ErrLinkExists", err)
+			}
+			if err := store.Replace("/a", "https://example.com/3"); err != nil {
+				t.Fatal(err)
+			}
+			if url, _, _ := store.Get("/a"); url != "https://example.com/3" {
+				t.Errorf("Get(/a) = %q, want https://example.com/3", url)
+			}
+		})
+	}
+}
+
+func TestMapStorePutIfAbsentConcurrent(t *testing.T) {
+	store := NewMapStore(nil)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		wins int
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if store.PutIfAbsent("/a", "https://example.com/a") == nil {
+				mu.Lock()
+				wins++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if wins != 1 {
+		t.Errorf("%d concurrent PutIfAbsent calls succeeded, want 1", wins)
+	}
+}
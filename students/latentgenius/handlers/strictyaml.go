@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+)
+
+// yamlFields are the fields a YAML entry may have.
+var yamlFields = map[string]bool{
+	"path":                true,
+	"url":                 true,
+	"base":                true,
+	"suffix":              true,
+	"gone":                true,
+	"legal":               true,
+	"authority":           true,
+	"ignore_case":         true,
+	"strict":              true,
+	"status":              true,
+	"rate_limit":          true,
+	"deprecated":          true,
+	"deprecation_message": true,
+}
+
+// WithStrictYAML makes YAMLHandler reject entries with unknown fields,
+// so a typo such as ur: for url: is reported at load time instead of
+// silently leaving the entry without a destination. The error names the
+// field and the entry. By default unknown fields are ignored.
+func WithStrictYAML() Option {
+	return func(o *options) {
+		o.strictYAML = true
+	}
+}
+
+// parseYAMLStrict is parseYAML rejecting unknown fields. It doesn't use
+// yaml.UnmarshalStrict, which also rejects the fields of an entry
+// overriding those merged in from an anchor with <<, the documented way
+// of sharing a base.
+func parseYAMLStrict(yaml []byte) ([]map[string]string, error) {
+	dst, err := parseYAML(yaml)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range dst {
+		var unknown []string
+		for field := range entry {
+			if !yamlFields[field] {
+				unknown = append(unknown, field)
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return nil, fmt.Errorf("entry %d (path %q): unknown field %q", i+1, entry["path"], unknown[0])
+		}
+	}
+	return dst, nil
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const sourceTemplate = "template"
+
+// TemplateData is what destination templates are evaluated with.
+type TemplateData struct {
+	Host   string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Now    time.Time
+	// Params holds the segments captured by the wildcards of the
+	// matched path pattern, by name, unescaped; nil for an exact path.
+	Params map[string]string
+}
+
+// TemplateHandler will return an http.HandlerFunc that redirects each
+// mapped path to the URL produced by its destination, a text/template
+// evaluated per request with a TemplateData, for destinations that need
+// runtime values:
+//
+//	"/today":  "https://news.example/{{.Now.Format \"2006/01/02\"}}",
+//	"/search": "https://search.example/?q={{.Query.Get \"q\" | urlquery}}",
+//	"/lang":   "https://docs.example/{{.Header.Get \"Accept-Language\" | urlquery}}/",
+//
+// A path may be a pattern capturing path segments into Params: {name}
+// matches one non-empty segment, and {name...}, only allowed last, the
+// rest of the path, slashes included:
+//
+//	"/gh/{owner}/{repo}": "https://github.com/{{.Params.owner}}/{{.Params.repo}}",
+//	"/docs/{page...}":    "https://docs.example/v2/{{.Params.page}}",
+//
+// Exact paths take precedence over patterns, and patterns are tried in
+// sorted order, the first match winning.
+//
+// Templates are compiled once, here; an error is returned for the
+// first one, or the first pattern, that doesn't parse, in sorted path
+// order. A template that
+// fails when executed doesn't fail the request: the error is logged and
+// the request falls through to the fallback, as for an unmapped path.
+func TemplateHandler(pathsToTemplates map[string]string, fallback http.Handler, opts ...Option) (http.HandlerFunc, error) {
+	o := newOptions(opts)
+	fallback = o.missFallback(sourceTemplate, fallback)
+	templates := make(map[string]*template.Template, len(pathsToTemplates))
+	var patterns []templatePattern
+	paths := make([]string, 0, len(pathsToTemplates))
+	for path := range pathsToTemplates {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		tmpl, err := template.New(path).Parse(pathsToTemplates[path])
+		if err != nil {
+			return nil, fmt.Errorf("template for %s: %v", path, err)
+		}
+		if !strings.Contains(path, "{") {
+			templates[path] = tmpl
+			continue
+		}
+		segs, err := parsePattern(path)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, templatePattern{segs, tmpl})
+	}
+
+	return o.handler(sourceTemplate, func(w http.ResponseWriter, r *http.Request) {
+		if o.serveRoot(w, r, sourceTemplate, fallback) {
+			return
+		}
+		var params map[string]string
+		tmpl, ok := templates[r.URL.Path]
+		for i := 0; !ok && i < len(patterns); i++ {
+			if params, ok = patterns[i].match(r.URL.Path); ok {
+				tmpl = patterns[i].tmpl
+			}
+		}
+		if !ok {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		var dst bytes.Buffer
+		err := tmpl.Execute(&dst, TemplateData{
+			Host:   r.Host,
+			Path:   r.URL.Path,
+			Query:  r.URL.Query(),
+			Header: r.Header,
+			Now:    time.Now(),
+			Params: params,
+		})
+		if err != nil {
+			log.Printf("%s: path %s: %v", sourceTemplate, r.URL.Path, err)
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		if o.blank(w, r, sourceTemplate, dst.String(), fallback) {
+			return
+		}
+		o.redirect(w, r, sourceTemplate, dst.String(), http.StatusFound, fallback)
+	}), nil
+}
+
+// templatePattern is a TemplateHandler path with wildcards.
+type templatePattern struct {
+	segs []string // path segments, wildcards in braces
+	tmpl *template.Template
+}
+
+// parsePattern splits the pattern path into its segments, checking its
+// wildcards.
+func parsePattern(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("pattern %s: must start with /", path)
+	}
+	segs := strings.Split(path[1:], "/")
+	for i, seg := range segs {
+		name, ok := wildcard(seg)
+		switch {
+		case !ok && strings.ContainsAny(seg, "{}"):
+			return nil, fmt.Errorf("pattern %s: segment %q must be a whole {name}", path, seg)
+		case ok && strings.TrimSuffix(name, "...") == "":
+			return nil, fmt.Errorf("pattern %s: wildcard %q has no name", path, seg)
+		case ok && strings.HasSuffix(name, "...") && i != len(segs)-1:
+			return nil, fmt.Errorf("pattern %s: %s must be the last segment", path, seg)
+		}
+	}
+	return segs, nil
+}
+
+// wildcard returns the name in the {name} segment seg, and whether seg
+// is one.
+func wildcard(seg string) (string, bool) {
+	if len(seg) < 2 || seg[0] != '{' || seg[len(seg)-1] != '}' {
+		return "", false
+	}
+	return seg[1 : len(seg)-1], true
+}
+
+// match reports whether path matches the pattern, and returns what its
+// wildcards captured.
+func (p templatePattern) match(path string) (map[string]string, bool) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, false
+	}
+	parts := strings.Split(path[1:], "/")
+	params := make(map[string]string)
+	for i, seg := range p.segs {
+		name, ok := wildcard(seg)
+		if i >= len(parts) {
+			return nil, false
+		}
+		if ok && strings.HasSuffix(name, "...") {
+			rest := strings.Join(parts[i:], "/")
+			if rest == "" {
+				return nil, false
+			}
+			params[strings.TrimSuffix(name, "...")] = rest
+			return params, true
+		}
+		if !ok {
+			if parts[i] != seg {
+				return nil, false
+			}
+			continue
+		}
+		if parts[i] == "" {
+			return nil, false
+		}
+		params[name] = parts[i]
+	}
+	return params, len(parts) == len(p.segs)
+}
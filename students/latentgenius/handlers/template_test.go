@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplateHandler(t *testing.T) {
+	h, err := TemplateHandler(map[string]string{
+		"/today":             `https://news.example/{{.Now.Format "2006"}}`,
+		"/search":            `https://search.example/?q={{.Query.Get "q" | urlquery}}`,
+		"/lang":              `https://docs.example/{{.Header.Get "Accept-Language"}}/`,
+		"/gh/{owner}/{repo}": `https://github.com/{{.Params.owner}}/{{.Params.repo}}`,
+		"/gh/golang/go":      `https://go.dev/`,
+		"/docs/{page...}":    `https://docs.example/v2/{{.Params.page}}`,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		target   string
+		status   int
+		location string
+	}{
+		{"/today", http.StatusFound, "https://news.example/" + time.Now().Format("2006")},
+		{"/search?q=a+b", http.StatusFound, "https://search.example/?q=a+b"},
+		{"/lang", http.StatusFound, "https://docs.example/fr/"},
+		{"/gh/gophercises/urlshort", http.StatusFound, "https://github.com/gophercises/urlshort"},
+		{"/gh/golang/go", http.StatusFound, "https://go.dev/"},
+		{"/gh/gophercises", http.StatusNotFound, ""},
+		{"/gh/gophercises/urlshort/issues", http.StatusNotFound, ""},
+		{"/docs/install/linux", http.StatusFound, "https://docs.example/v2/install/linux"},
+		{"/docs/", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.target, nil)
+		r.Header.Set("Accept-Language", "fr")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if rec.Code != tt.status || rec.Header().Get("Location") != tt.location {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.target, rec.Code, rec.Header().Get("Location"), tt.status, tt.location)
+		}
+	}
+}
+
+func TestTemplateHandlerExecError(t *testing.T) {
+	var logs bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logs)
+
+	home := http.RedirectHandler("https://example.com/", http.StatusSeeOther)
+	h, err := TemplateHandler(map[string]string{"/bad": `https://example.com/{{index .Query "q" 5}}`}, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/bad?q=x", nil))
+	if rec.Code != http.StatusSeeOther {
+		t.Errorf("GET /bad = %d, want the fallback's %d", rec.Code, http.StatusSeeOther)
+	}
+	if !strings.Contains(logs.String(), "/bad") {
+		t.Errorf("log = %q, want the failing path logged", logs.String())
+	}
+}
+
+func TestTemplateHandlerParseErrors(t *testing.T) {
+	for _, path := range []string{"/x", "/a/{rest...}/b", "/a/{}", "/a/b{c}"} {
+		tmpl := "https://example.com/"
+		if path == "/x" {
+			tmpl = "https://example.com/{{"
+		}
+		if _, err := TemplateHandler(map[string]string{path: tmpl}, nil); err == nil {
+			t.Errorf("TemplateHandler(%q: %q) succeeded, want an error", path, tmpl)
+		}
+	}
+}
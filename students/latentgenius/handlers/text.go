@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const sourceText = "text"
+
+// TextHandler will parse a plain text mapping and return an
+// http.HandlerFunc that redirects its paths, like MapHandler. The text
+// holds one path and its URL per line, separated by spaces or tabs,
+// for the quickest of setups:
+//
+//	# docs
+//	/godoc   https://godoc.org/github.com/gophercises/urlshort
+//	/yaml    https://godoc.org/gopkg.in/yaml.v2  # the YAML library
+//
+// Blank lines and lines starting with # are ignored, as is anything
+// after a # that starts a third field on a line. Errors, such as a line
+// without a URL or a path given twice, report the line number.
+func TextHandler(text []byte, fallback http.Handler, opts ...Option) (http.HandlerFunc, error) {
+	o := newOptions(opts)
+	m, err := parseText(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.checkEntries(len(m.urls)); err != nil {
+		return nil, err
+	}
+	return mappingHandler(sourceText, m, fallback, o), nil
+}
+
+func parseText(text []byte) (*mapping, error) {
+	var entries []map[string]string
+	definedOn := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(text))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("text: line %d: no url for path %s", line, fields[0])
+		}
+		if len(fields) > 2 && !strings.HasPrefix(fields[2], "#") {
+			return nil, fmt.Errorf("text: line %d: want a path and a url, got %d fields", line, len(fields))
+		}
+		if other, ok := definedOn[fields[0]]; ok {
+			return nil, fmt.Errorf("text: line %d: path %s is also defined on line %d", line, fields[0], other)
+		}
+		definedOn[fields[0]] = line
+		entries = append(entries, map[string]string{"path": fields[0], "url": fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("text: %v", err)
+	}
+	return buildMapping(entries)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracer makes the handler start a span from tracer around each
+// request, recording the request path, whether a mapping matched, the
+// destination and the response status. Without this option no spans
+// are created.
+func WithTracer(tracer trace.Tracer) Option {
+	return func(o *options) {
+		o.tracer = tracer
+	}
+}
+
+// instrument wraps h in a span per request when a tracer is configured.
+func (o *options) instrument(source string, h http.HandlerFunc) http.HandlerFunc {
+	if o.tracer == nil {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := o.tracer.Start(r.Context(), "urlshort."+source)
+		defer span.End()
+		span.SetAttributes(
+			attribute.String("urlshort.source", source),
+			attribute.String("urlshort.path", r.URL.Path),
+			attribute.Bool("urlshort.matched", false),
+		)
+		sw := NewStatusWriter(w)
+		h(sw, r.WithContext(ctx))
+		span.SetAttributes(attribute.Int("http.status_code", sw.Status()))
+	}
+}
+
+// traceRedirect records a matched redirect on the request's span, if
+// it has one.
+func traceRedirect(r *http.Request, url string) {
+	span := trace.SpanFromContext(r.Context())
+	if !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(
+		attribute.Bool("urlshort.matched", true),
+		attribute.String("urlshort.destination", url),
+	)
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import "net/http"
+
+// DestinationTrailer is the response trailer carrying the destination
+// of a redirect, see WithDestinationTrailer.
+const DestinationTrailer = "X-Resolved-Destination"
+
+// WithDestinationTrailer makes the handler send the destination of each
+// redirect in an X-Resolved-Destination trailer, and the rule that
+// matched, as named by WithMatchedRule, in an X-Matched-Rule trailer,
+// for proxies that route on trailers. It is off by default.
+//
+// Trailers come after the body: the response announces them in its
+// Trailer header, and on HTTP/1.1 it is sent with chunked encoding,
+// without a Content-Length, so the trailers can follow the last chunk.
+// A response without a body, such as the one to a HEAD request, has
+// no trailers, and clients or proxies that don't read trailers never
+// see them.
+func WithDestinationTrailer() Option {
+	return func(o *options) {
+		o.trailer = true
+	}
+}
+
+// setDestinationTrailer declares the trailers of a redirect to dst and
+// sets their values, to be sent after the body.
+func (o *options) setDestinationTrailer(w http.ResponseWriter, dst string) {
+	if !o.trailer {
+		return
+	}
+	h := w.Header()
+	declared := DestinationTrailer
+	_, header := h[MatchedRuleHeader]
+	_, trailer := h[http.TrailerPrefix+MatchedRuleHeader]
+	if header || trailer {
+		declared += ", " + MatchedRuleHeader
+	}
+	h.Add("Trailer", declared)
+	h[http.TrailerPrefix+DestinationTrailer] = []string{dst}
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDestinationTrailer(t *testing.T) {
+	pathsToUrls := map[string]string{"/a": "https://example.com/a"}
+	tests := []struct {
+		name   string
+		opts   []Option
+		header string
+	}{
+		{"trailer only", []Option{WithDestinationTrailer()}, ""},
+		{"with matched rule", []Option{WithDestinationTrailer(), WithMatchedRule()}, "/a"},
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(MapHandler(pathsToUrls, nil, tt.opts...))
+			defer srv.Close()
+			resp, err := client.Get(srv.URL + "/a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+
+			if got := resp.Header.Get(MatchedRuleHeader); got != tt.header {
+				t.Errorf("header %s = %q, want %q", MatchedRuleHeader, got, tt.header)
+			}
+			want := http.Header{
+				DestinationTrailer: {"https://example.com/a"},
+				MatchedRuleHeader:  {"/a"},
+			}
+			if !reflect.DeepEqual(resp.Trailer, want) {
+				t.Errorf("trailer = %v, want %v", resp.Trailer, want)
+			}
+		})
+	}
+}
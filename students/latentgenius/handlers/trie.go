@@ -0,0 +1,94 @@
+package handlers
+
+import "strings"
+
+// prefixTrie indexes path prefixes by path segment, so that finding the
+// longest prefix matching a path takes time proportional to the length
+// of the path rather than to the number of prefixes.
+//
+// Each node holds up to two prefixes ending at its segment: one without
+// a trailing slash (/blog, matching /blog and anything below it) and
+// one with (/blog/, matching only below it, and /blog/ itself).
+type prefixTrie struct {
+	root trieNode
+}
+
+type trieNode struct {
+	children map[string]*trieNode
+	bare     string // prefix ending here without a trailing slash
+	hasBare  bool
+	slash    string // prefix ending here with a trailing slash
+	hasSlash bool
+}
+
+func newPrefixTrie(prefixes []string) *prefixTrie {
+	t := &prefixTrie{}
+	for _, p := range prefixes {
+		t.insert(p)
+	}
+	return t
+}
+
+// insert adds prefix to the trie. Prefixes that don't start with a
+// slash can never match a request path and are ignored, except for the
+// empty prefix, which matches everything.
+func (t *prefixTrie) insert(prefix string) {
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		return
+	}
+	trailing := strings.HasSuffix(prefix, "/")
+	body := strings.TrimSuffix(strings.TrimPrefix(prefix, "/"), "/")
+	n := &t.root
+	if body != "" {
+		for _, seg := range strings.Split(body, "/") {
+			child, ok := n.children[seg]
+			if !ok {
+				if n.children == nil {
+					n.children = make(map[string]*trieNode)
+				}
+				child = &trieNode{}
+				n.children[seg] = child
+			}
+			n = child
+		}
+	}
+	if trailing {
+		n.slash, n.hasSlash = prefix, true
+	} else {
+		n.bare, n.hasBare = prefix, true
+	}
+}
+
+// longest returns the longest prefix matching path on a segment
+// boundary, and whether there is one.
+func (t *prefixTrie) longest(path string) (string, bool) {
+	n := &t.root
+	best, found := n.bare, n.hasBare
+	if !strings.HasPrefix(path, "/") {
+		return best, found
+	}
+	if n.hasSlash {
+		best, found = n.slash, true
+	}
+	rest := path[1:]
+	for rest != "" {
+		seg := rest
+		more := false
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			seg, rest, more = rest[:i], rest[i+1:], true
+		} else {
+			rest = ""
+		}
+		child, ok := n.children[seg]
+		if !ok {
+			break
+		}
+		n = child
+		if more && n.hasSlash {
+			best, found = n.slash, true
+		} else if n.hasBare {
+			best, found = n.bare, true
+		}
+	}
+	return best, found
+}
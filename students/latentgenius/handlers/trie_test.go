@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// longestLinear is the scan the trie replaces: it checks every prefix
+// against path. It is the reference the trie is tested against.
+func longestLinear(prefixes []string, path string) (string, bool) {
+	best, found := "", false
+	for _, p := range prefixes {
+		if !matchesPrefix(p, path) {
+			continue
+		}
+		if !found || len(p) > len(best) {
+			best, found = p, true
+		}
+	}
+	return best, found
+}
+
+// matchesPrefix reports whether prefix matches path on a segment
+// boundary.
+func matchesPrefix(prefix, path string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return prefix == "" || strings.HasSuffix(prefix, "/") || len(path) == len(prefix) || path[len(prefix)] == '/'
+}
+
+func TestPrefixTrie(t *testing.T) {
+	prefixes := []string{"", "/blog", "/blog/", "/blog/2020", "/docs/", "/a/b/c", "relative"}
+	trie := newPrefixTrie(prefixes)
+	paths := []string{"/", "/blog", "/blog/", "/blogger", "/blog/2020", "/blog/2020/01",
+		"/blog/2021", "/docs", "/docs/", "/docs/x", "/a/b", "/a/b/c/d", "relative", "/relative"}
+	for _, path := range paths {
+		gotP, gotOK := trie.longest(path)
+		wantP, wantOK := longestLinear(prefixes[:len(prefixes)-1], path)
+		if gotP != wantP || gotOK != wantOK {
+			t.Errorf("longest(%q) = %q, %v, want %q, %v", path, gotP, gotOK, wantP, wantOK)
+		}
+	}
+}
+
+// benchPrefixes returns n prefixes two segments deep, and a path
+// matching the last of them.
+func benchPrefixes(n int) ([]string, string) {
+	prefixes := make([]string, n)
+	for i := range prefixes {
+		prefixes[i] = fmt.Sprintf("/section%d/page%d/", i%100, i)
+	}
+	return prefixes, fmt.Sprintf("/section%d/page%d/rest/of/path", (n-1)%100, n-1)
+}
+
+func BenchmarkPrefixTrie(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		prefixes, path := benchPrefixes(n)
+		trie := newPrefixTrie(prefixes)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				trie.longest(path)
+			}
+		})
+	}
+}
+
+func BenchmarkPrefixLinear(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		prefixes, path := benchPrefixes(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				longestLinear(prefixes, path)
+			}
+		})
+	}
+}
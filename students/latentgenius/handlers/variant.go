@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// variantCookieMaxAge is how long a variant cookie lasts: long enough to
+// cover a visit, short enough not to pin a visitor to a variant.
+const variantCookieMaxAge = time.Hour
+
+// Attribution says how RoundRobinHandler records which mirror, or
+// variant, served a request.
+type Attribution int
+
+const (
+	// AttributeCookie sets a short-lived cookie naming the variant. It
+	// is only readable by destinations on the shortener's own site.
+	AttributeCookie Attribution = iota
+	// AttributeQuery appends the variant as a query parameter to the
+	// destination URL, so any destination can read it.
+	AttributeQuery
+)
+
+// WithVariantAttribution makes RoundRobinHandler record the variant it
+// redirected to, so conversions on the destination can be attributed
+// to it: as a cookie or query parameter called name, according to how.
+// The variant is the mirror's Name, or its index in the path's mirrors
+// if it has none. Redirect events sent to a webhook carry the marked
+// destination, so impressions can be counted per variant there.
+func WithVariantAttribution(how Attribution, name string) Option {
+	return func(o *options) {
+		o.attribution = how
+		o.variantName = name
+	}
+}
+
+// markVariant records variant according to the attribution options and
+// returns the destination to redirect to.
+func (o *options) markVariant(w http.ResponseWriter, dst, variant string) string {
+	if o.variantName == "" {
+		return dst
+	}
+	if o.attribution == AttributeCookie {
+		http.SetCookie(w, &http.Cookie{
+			Name:     o.variantName,
+			Value:    variant,
+			Path:     "/",
+			MaxAge:   int(variantCookieMaxAge / time.Second),
+			HttpOnly: true,
+		})
+		return dst
+	}
+	return setQueryParam(dst, o.variantName, variant)
+}
+
+// setQueryParam sets the query parameter name of the URL dst to value,
+// leaving dst alone if it doesn't parse.
+func setQueryParam(dst, name, value string) string {
+	u, err := url.Parse(dst)
+	if err != nil {
+		return dst
+	}
+	query := u.Query()
+	query.Set(name, value)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// variantOf names mirror i of mirrors.
+func variantOf(mirrors []Mirror, i int) string {
+	if mirrors[i].Name != "" {
+		return mirrors[i].Name
+	}
+	return strconv.Itoa(i)
+}
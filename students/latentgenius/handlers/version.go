@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// versionLength is the number of hex digits of a mapping version.
+const versionLength = 8
+
+// WithVersionParam makes the map-based handlers append a cache-busting
+// query parameter called name to their destinations, such as
+// ?v=3f2a9c1e, whose value is a hash of the whole mapping: it changes
+// whenever the mapping does, so clients caching a static destination
+// aggressively fetch it again after any change, including reloads of
+// RemoteHandler, SheetHandler, ConsulHandler and the DB snapshot. It is
+// off by default.
+func WithVersionParam(name string) Option {
+	return func(o *options) {
+		o.versionParam = name
+	}
+}
+
+// version returns the content hash of the mapping's destinations.
+func (m *mapping) version() string {
+	paths := make([]string, 0, len(m.urls))
+	for path := range m.urls {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, path := range paths {
+		h.Write([]byte(path))
+		h.Write([]byte{0})
+		h.Write([]byte(m.urls[path]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:versionLength]
+}
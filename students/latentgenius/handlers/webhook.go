@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RedirectEvent describes a matched redirect, as posted to a Webhook.
+type RedirectEvent struct {
+	Time        time.Time `json:"time"`
+	Source      string    `json:"source"`
+	Host        string    `json:"host"`
+	Path        string    `json:"path"`
+	Destination string    `json:"destination"`
+	Status      int       `json:"status"`
+}
+
+// QueueFullPolicy says what a Webhook does with an event when its queue
+// is full.
+type QueueFullPolicy int
+
+const (
+	// DropNewest discards the event that didn't fit.
+	DropNewest QueueFullPolicy = iota
+	// DropOldest discards the oldest queued event to make room.
+	DropOldest
+)
+
+// WebhookConfig configures a Webhook. Zero values get defaults.
+type WebhookConfig struct {
+	URL       string
+	Workers   int             // concurrent POSTs; defaults to 4
+	QueueSize int             // pending events; defaults to 1000
+	OnFull    QueueFullPolicy // defaults to DropNewest
+	Client    *http.Client    // defaults to a client with a 10s timeout
+}
+
+// Webhook posts RedirectEvents as JSON to an HTTP endpoint, from a
+// bounded pool of workers, so a slow endpoint never holds up request
+// handling: when the queue is full, events are dropped according to
+// the configured policy.
+type Webhook struct {
+	cfg     WebhookConfig
+	queue   chan RedirectEvent
+	mu      sync.Mutex   // serializes enqueueing under DropOldest
+	closeMu sync.RWMutex // read-held by Send, so Close can't close queue under it
+	closed  bool
+	dropped uint64 // atomic
+	wg      sync.WaitGroup
+}
+
+// NewWebhook starts the workers of a Webhook posting to cfg.URL.
+func NewWebhook(cfg WebhookConfig) *Webhook {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	wh := &Webhook{
+		cfg:   cfg,
+		queue: make(chan RedirectEvent, cfg.QueueSize),
+	}
+	wh.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go wh.work()
+	}
+	return wh
+}
+
+// WithWebhook makes the handler send a RedirectEvent to wh for every
+// matched redirect.
+func WithWebhook(wh *Webhook) Option {
+	return func(o *options) {
+		o.webhook = wh
+	}
+}
+
+// Send queues ev for delivery without blocking. Events sent after
+// Close, as by handlers still serving while a server shuts down, are
+// dropped.
+func (wh *Webhook) Send(ev RedirectEvent) {
+	wh.closeMu.RLock()
+	defer wh.closeMu.RUnlock()
+	if wh.closed {
+		atomic.AddUint64(&wh.dropped, 1)
+		return
+	}
+	select {
+	case wh.queue <- ev:
+		return
+	default:
+	}
+	if wh.cfg.OnFull != DropOldest {
+		atomic.AddUint64(&wh.dropped, 1)
+		return
+	}
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	for {
+		select {
+		case wh.queue <- ev:
+			return
+		default:
+		}
+		select {
+		case <-wh.queue:
+			atomic.AddUint64(&wh.dropped, 1)
+		default:
+		}
+	}
+}
+
+// Dropped returns the number of events dropped so far, because the
+// queue was full or the Webhook closed.
+func (wh *Webhook) Dropped() uint64 {
+	return atomic.LoadUint64(&wh.dropped)
+}
+
+// Close stops accepting events and waits for the queued ones to be
+// delivered.
+func (wh *Webhook) Close() {
+	wh.closeMu.Lock()
+	if !wh.closed {
+		wh.closed = true
+		close(wh.queue)
+	}
+	wh.closeMu.Unlock()
+	wh.wg.Wait()
+}
+
+func (wh *Webhook) work() {
+	defer wh.wg.Done()
+	for ev := range wh.queue {
+		if err := wh.post(ev); err != nil {
+			log.Printf("webhook %s: %v", wh.cfg.URL, err)
+		}
+	}
+}
+
+func (wh *Webhook) post(ev RedirectEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	resp, err := wh.cfg.Client.Post(wh.cfg.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
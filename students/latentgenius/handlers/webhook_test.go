@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestWebhook(t *testing.T) {
+	events := make(chan RedirectEvent, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev RedirectEvent
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Error(err)
+		}
+		events <- ev
+	}))
+	defer srv.Close()
+
+	wh := NewWebhook(WebhookConfig{URL: srv.URL})
+	h := MapHandler(map[string]string{"/a": "https://example.com/a"}, nil, WithWebhook(wh))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/miss", nil))
+	wh.Close()
+
+	ev := <-events
+	if ev.Source != sourceMap || ev.Path != "/a" || ev.Destination != "https://example.com/a" || ev.Status != http.StatusFound {
+		t.Errorf("event = %+v, want a 302 from map for /a", ev)
+	}
+	if len(events) != 0 {
+		t.Error("the miss was posted too")
+	}
+}
+
+func TestWebhookQueueFull(t *testing.T) {
+	for _, policy := range []QueueFullPolicy{DropNewest, DropOldest} {
+		block := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-block
+		}))
+		wh := NewWebhook(WebhookConfig{URL: srv.URL, Workers: 1, QueueSize: 2, OnFull: policy})
+		for i := 0; i < 10; i++ {
+			wh.Send(RedirectEvent{Path: "/a"})
+		}
+		// At most one event is with the worker and two are queued; the
+		// rest, at least 7, were dropped.
+		if n := wh.Dropped(); n < 7 {
+			t.Errorf("policy %d: %d events dropped, want at least 7", policy, n)
+		}
+		close(block)
+		wh.Close()
+		srv.Close()
+	}
+}
+
+func TestWebhookSendAfterClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	wh := NewWebhook(WebhookConfig{URL: srv.URL})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				wh.Send(RedirectEvent{Path: "/a"})
+			}
+		}()
+	}
+	wh.Close()
+	wg.Wait()
+	before := wh.Dropped()
+	wh.Send(RedirectEvent{Path: "/late"})
+	if wh.Dropped() != before+1 {
+		t.Errorf("late event: dropped went from %d to %d, want one more", before, wh.Dropped())
+	}
+	wh.Close()
+}